@@ -0,0 +1,269 @@
+package collector_mongod
+
+import (
+	"sort"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	// connPoolOtherHost is the host label of the hosts over the cardinality limit
+	connPoolOtherHost = "other"
+)
+
+var (
+	connPoolConnections = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "connpool",
+		Name:      "connections",
+		Help:      "The number of outgoing connections in use, available and refreshing in all connection pools",
+	}, []string{"state"})
+	connPoolConnectionsCreatedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "connpool",
+		Name:      "connections_created_total",
+		Help:      "The total number of outgoing connections created by all connection pools",
+	})
+	connPoolPoolConnections = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "connpool",
+		Name:      "pool_connections",
+		Help:      "The number of outgoing connections in use, available and refreshing per connection pool",
+	}, []string{"pool", "state"})
+	connPoolPoolConnectionsCreatedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "connpool",
+		Name:      "pool_connections_created_total",
+		Help:      "The total number of outgoing connections created per connection pool",
+	}, []string{"pool"})
+	connPoolHostConnections = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "connpool",
+		Name:      "host_connections",
+		Help:      "The number of outgoing connections in use, available and refreshing per remote host, hosts over the limit are summed as host \"other\"",
+	}, []string{"host", "state"})
+	connPoolHostConnectionsCreatedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "connpool",
+		Name:      "host_connections_created_total",
+		Help:      "The total number of outgoing connections created per remote host, hosts over the limit are summed as host \"other\"",
+	}, []string{"host"})
+	connPoolShardHostConnectionsAvailable = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "connpool",
+		Name:      "shard_host_connections_available",
+		Help:      "The number of available connections of the sharding connection pool per remote host reported by shardConnPoolStats (before 5.0)",
+	}, []string{"host"})
+	connPoolShardHostConnectionsCreatedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "connpool",
+		Name:      "shard_host_connections_created_total",
+		Help:      "The total number of connections created by the sharding connection pool per remote host reported by shardConnPoolStats (before 5.0)",
+	}, []string{"host"})
+	connPoolReplSetMemberUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "connpool",
+		Name:      "replset_member_up",
+		Help:      "Boolean reporting if the replica set monitor can reach the member (1 = ok/0 = not ok)",
+	}, []string{"set", "host"})
+	connPoolReplSetMemberPrimary = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "connpool",
+		Name:      "replset_member_primary",
+		Help:      "Boolean reporting if the replica set monitor sees the member as primary (1 = primary/0 = not primary)",
+	}, []string{"set", "host"})
+	connPoolReplSetMemberPingSeconds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "connpool",
+		Name:      "replset_member_ping_seconds",
+		Help:      "The round trip time to the member measured by the replica set monitor",
+	}, []string{"set", "host"})
+)
+
+// ConnPoolHostStats keeps the connection counters of a remote host
+type ConnPoolHostStats struct {
+	InUse      float64 `bson:"inUse"`
+	Available  float64 `bson:"available"`
+	Created    float64 `bson:"created"`
+	Refreshing float64 `bson:"refreshing"`
+}
+
+// ConnPoolPoolStats keeps the connection counters of a connection pool
+type ConnPoolPoolStats struct {
+	PoolInUse      float64 `bson:"poolInUse"`
+	PoolAvailable  float64 `bson:"poolAvailable"`
+	PoolCreated    float64 `bson:"poolCreated"`
+	PoolRefreshing float64 `bson:"poolRefreshing"`
+}
+
+// ConnPoolReplSetMember represents a host of the replica set monitor
+type ConnPoolReplSetMember struct {
+	Addr           string  `bson:"addr"`
+	Ok             bool    `bson:"ok"`
+	IsMaster       bool    `bson:"ismaster"`
+	PingTimeMillis float64 `bson:"pingTimeMillis"`
+}
+
+// ConnPoolReplSet keeps the replica set monitor state of a replica set
+type ConnPoolReplSet struct {
+	Hosts []ConnPoolReplSetMember `bson:"hosts"`
+}
+
+// ConnPoolStats keeps the data returned by the connPoolStats command
+type ConnPoolStats struct {
+	TotalInUse      float64                      `bson:"totalInUse"`
+	TotalAvailable  float64                      `bson:"totalAvailable"`
+	TotalCreated    float64                      `bson:"totalCreated"`
+	TotalRefreshing float64                      `bson:"totalRefreshing"`
+	Hosts           map[string]ConnPoolHostStats `bson:"hosts"`
+	Pools           map[string]bson.Raw          `bson:"pools"`
+	ReplicaSets     map[string]ConnPoolReplSet   `bson:"replicaSets"`
+
+	// Shard is the output of shardConnPoolStats, nil when it is not available
+	Shard *ShardConnPoolStats `bson:"-"`
+	// MaxHosts limits the number of host label values, see limitConnPoolHosts
+	MaxHosts int `bson:"-"`
+}
+
+// ShardConnPoolStats keeps the data returned by the shardConnPoolStats command (before 5.0)
+type ShardConnPoolStats struct {
+	Hosts map[string]ConnPoolHostStats `bson:"hosts"`
+}
+
+// limitConnPoolHosts keeps the first maxHosts hosts in sorted order and sums the
+// counters of the other hosts as connPoolOtherHost, maxHosts <= 0 keeps all hosts
+func limitConnPoolHosts(hosts map[string]ConnPoolHostStats, maxHosts int) map[string]ConnPoolHostStats {
+	if maxHosts <= 0 || len(hosts) <= maxHosts {
+		return hosts
+	}
+	names := make([]string, 0, len(hosts))
+	for name := range hosts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	limited := make(map[string]ConnPoolHostStats, maxHosts+1)
+	var other ConnPoolHostStats
+	for i, name := range names {
+		if i < maxHosts {
+			limited[name] = hosts[name]
+			continue
+		}
+		other.InUse += hosts[name].InUse
+		other.Available += hosts[name].Available
+		other.Created += hosts[name].Created
+		other.Refreshing += hosts[name].Refreshing
+	}
+	limited[connPoolOtherHost] = other
+	return limited
+}
+
+// Export exports the data to prometheus.
+func (stats *ConnPoolStats) Export(ch chan<- prometheus.Metric) {
+	connPoolPoolConnections.Reset()
+	connPoolPoolConnectionsCreatedTotal.Reset()
+	connPoolHostConnections.Reset()
+	connPoolHostConnectionsCreatedTotal.Reset()
+	connPoolShardHostConnectionsAvailable.Reset()
+	connPoolShardHostConnectionsCreatedTotal.Reset()
+	connPoolReplSetMemberUp.Reset()
+	connPoolReplSetMemberPrimary.Reset()
+	connPoolReplSetMemberPingSeconds.Reset()
+
+	connPoolConnections.WithLabelValues("in_use").Set(stats.TotalInUse)
+	connPoolConnections.WithLabelValues("available").Set(stats.TotalAvailable)
+	connPoolConnections.WithLabelValues("refreshing").Set(stats.TotalRefreshing)
+	connPoolConnectionsCreatedTotal.Set(stats.TotalCreated)
+
+	for name, raw := range stats.Pools {
+		pool := ConnPoolPoolStats{}
+		if err := raw.Unmarshal(&pool); err != nil {
+			glog.Errorf("Failed to decode the connPoolStats pool %s: %s", name, err)
+			continue
+		}
+		connPoolPoolConnections.WithLabelValues(name, "in_use").Set(pool.PoolInUse)
+		connPoolPoolConnections.WithLabelValues(name, "available").Set(pool.PoolAvailable)
+		connPoolPoolConnections.WithLabelValues(name, "refreshing").Set(pool.PoolRefreshing)
+		connPoolPoolConnectionsCreatedTotal.WithLabelValues(name).Set(pool.PoolCreated)
+	}
+
+	for host, hostStats := range limitConnPoolHosts(stats.Hosts, stats.MaxHosts) {
+		connPoolHostConnections.WithLabelValues(host, "in_use").Set(hostStats.InUse)
+		connPoolHostConnections.WithLabelValues(host, "available").Set(hostStats.Available)
+		connPoolHostConnections.WithLabelValues(host, "refreshing").Set(hostStats.Refreshing)
+		connPoolHostConnectionsCreatedTotal.WithLabelValues(host).Set(hostStats.Created)
+	}
+
+	if stats.Shard != nil {
+		for host, hostStats := range limitConnPoolHosts(stats.Shard.Hosts, stats.MaxHosts) {
+			connPoolShardHostConnectionsAvailable.WithLabelValues(host).Set(hostStats.Available)
+			connPoolShardHostConnectionsCreatedTotal.WithLabelValues(host).Set(hostStats.Created)
+		}
+	}
+
+	for set, replSet := range stats.ReplicaSets {
+		for _, member := range replSet.Hosts {
+			var up, primary float64
+			if member.Ok {
+				up = 1
+			}
+			if member.IsMaster {
+				primary = 1
+			}
+			connPoolReplSetMemberUp.WithLabelValues(set, member.Addr).Set(up)
+			connPoolReplSetMemberPrimary.WithLabelValues(set, member.Addr).Set(primary)
+			connPoolReplSetMemberPingSeconds.WithLabelValues(set, member.Addr).Set(member.PingTimeMillis / 1000)
+		}
+	}
+
+	connPoolConnections.Collect(ch)
+	connPoolConnectionsCreatedTotal.Collect(ch)
+	connPoolPoolConnections.Collect(ch)
+	connPoolPoolConnectionsCreatedTotal.Collect(ch)
+	connPoolHostConnections.Collect(ch)
+	connPoolHostConnectionsCreatedTotal.Collect(ch)
+	connPoolShardHostConnectionsAvailable.Collect(ch)
+	connPoolShardHostConnectionsCreatedTotal.Collect(ch)
+	connPoolReplSetMemberUp.Collect(ch)
+	connPoolReplSetMemberPrimary.Collect(ch)
+	connPoolReplSetMemberPingSeconds.Collect(ch)
+}
+
+// Describe describes the metrics for prometheus
+func (stats *ConnPoolStats) Describe(ch chan<- *prometheus.Desc) {
+	connPoolConnections.Describe(ch)
+	connPoolConnectionsCreatedTotal.Describe(ch)
+	connPoolPoolConnections.Describe(ch)
+	connPoolPoolConnectionsCreatedTotal.Describe(ch)
+	connPoolHostConnections.Describe(ch)
+	connPoolHostConnectionsCreatedTotal.Describe(ch)
+	connPoolShardHostConnectionsAvailable.Describe(ch)
+	connPoolShardHostConnectionsCreatedTotal.Describe(ch)
+	connPoolReplSetMemberUp.Describe(ch)
+	connPoolReplSetMemberPrimary.Describe(ch)
+	connPoolReplSetMemberPingSeconds.Describe(ch)
+}
+
+// GetConnPoolStats returns the output of connPoolStats and shardConnPoolStats
+// with the host label values limited to maxHosts
+func GetConnPoolStats(session *mgo.Session, maxHosts int) *ConnPoolStats {
+	stats := &ConnPoolStats{}
+	err := session.DB("admin").Run(bson.D{{"connPoolStats", 1}}, stats)
+	if err != nil {
+		glog.Errorf("Failed to get connPoolStats: %s", err)
+		return nil
+	}
+	stats.MaxHosts = maxHosts
+
+	shardStats := &ShardConnPoolStats{}
+	err = session.DB("admin").Run(bson.D{{"shardConnPoolStats", 1}}, shardStats)
+	if err != nil {
+		glog.Infof("Failed to get shardConnPoolStats: %s", err)
+	} else {
+		stats.Shard = shardStats
+	}
+	return stats
+}
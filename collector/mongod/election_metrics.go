@@ -0,0 +1,113 @@
+package collector_mongod
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	electionCalledTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "election",
+		Name:      "called_total",
+		Help:      "The total number of elections called by this member per election reason",
+	}, []string{"reason"})
+	electionSuccessfulTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "election",
+		Name:      "successful_total",
+		Help:      "The total number of elections called and won by this member per election reason",
+	}, []string{"reason"})
+	electionStepDownsCausedByHigherTermTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "election",
+		Name:      "step_downs_caused_by_higher_term_total",
+		Help:      "The total number of times this member stepped down because it saw a higher term",
+	})
+	electionCatchUpsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "election",
+		Name:      "catch_ups_total",
+		Help:      "The total number of catchup phases run by this member as a newly elected primary",
+	})
+	electionCatchUpResultsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "election",
+		Name:      "catch_up_results_total",
+		Help:      "The total number of catchup phases run by this member as a newly elected primary per outcome",
+	}, []string{"result"})
+	electionAverageCatchUpOps = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "election",
+		Name:      "average_catch_up_ops",
+		Help:      "The average number of operations applied during the catchup phases of this member",
+	})
+)
+
+// ElectionReasonStats keeps the counters of an election reason
+type ElectionReasonStats struct {
+	Called     float64 `bson:"called"`
+	Successful float64 `bson:"successful"`
+}
+
+// ElectionMetrics keeps the data of the serverStatus electionMetrics section (4.2+)
+type ElectionMetrics struct {
+	StepUpCmd                                   *ElectionReasonStats `bson:"stepUpCmd"`
+	PriorityTakeover                            *ElectionReasonStats `bson:"priorityTakeover"`
+	CatchUpTakeover                             *ElectionReasonStats `bson:"catchUpTakeover"`
+	ElectionTimeout                             *ElectionReasonStats `bson:"electionTimeout"`
+	FreezeTimeout                               *ElectionReasonStats `bson:"freezeTimeout"`
+	NumStepDownsCausedByHigherTerm              float64              `bson:"numStepDownsCausedByHigherTerm"`
+	NumCatchUps                                 float64              `bson:"numCatchUps"`
+	NumCatchUpsSucceeded                        float64              `bson:"numCatchUpsSucceeded"`
+	NumCatchUpsAlreadyCaughtUp                  float64              `bson:"numCatchUpsAlreadyCaughtUp"`
+	NumCatchUpsSkipped                          float64              `bson:"numCatchUpsSkipped"`
+	NumCatchUpsTimedOut                         float64              `bson:"numCatchUpsTimedOut"`
+	NumCatchUpsFailedWithError                  float64              `bson:"numCatchUpsFailedWithError"`
+	NumCatchUpsFailedWithNewTerm                float64              `bson:"numCatchUpsFailedWithNewTerm"`
+	NumCatchUpsFailedWithReplSetAbortPrimaryCmd float64              `bson:"numCatchUpsFailedWithReplSetAbortPrimaryCatchUpCmd"`
+	AverageCatchUpOps                           float64              `bson:"averageCatchUpOps"`
+}
+
+// Export exports the data to prometheus.
+func (stats *ElectionMetrics) Export(ch chan<- prometheus.Metric) {
+	for reason, reasonStats := range map[string]*ElectionReasonStats{
+		"step_up_cmd":       stats.StepUpCmd,
+		"priority_takeover": stats.PriorityTakeover,
+		"catch_up_takeover": stats.CatchUpTakeover,
+		"election_timeout":  stats.ElectionTimeout,
+		"freeze_timeout":    stats.FreezeTimeout,
+	} {
+		if reasonStats == nil {
+			continue
+		}
+		electionCalledTotal.WithLabelValues(reason).Set(reasonStats.Called)
+		electionSuccessfulTotal.WithLabelValues(reason).Set(reasonStats.Successful)
+	}
+	electionStepDownsCausedByHigherTermTotal.Set(stats.NumStepDownsCausedByHigherTerm)
+	electionCatchUpsTotal.Set(stats.NumCatchUps)
+	electionCatchUpResultsTotal.WithLabelValues("succeeded").Set(stats.NumCatchUpsSucceeded)
+	electionCatchUpResultsTotal.WithLabelValues("already_caught_up").Set(stats.NumCatchUpsAlreadyCaughtUp)
+	electionCatchUpResultsTotal.WithLabelValues("skipped").Set(stats.NumCatchUpsSkipped)
+	electionCatchUpResultsTotal.WithLabelValues("timed_out").Set(stats.NumCatchUpsTimedOut)
+	electionCatchUpResultsTotal.WithLabelValues("failed_with_error").Set(stats.NumCatchUpsFailedWithError)
+	electionCatchUpResultsTotal.WithLabelValues("failed_with_new_term").Set(stats.NumCatchUpsFailedWithNewTerm)
+	electionCatchUpResultsTotal.WithLabelValues("failed_with_abort_cmd").Set(stats.NumCatchUpsFailedWithReplSetAbortPrimaryCmd)
+	electionAverageCatchUpOps.Set(stats.AverageCatchUpOps)
+
+	electionCalledTotal.Collect(ch)
+	electionSuccessfulTotal.Collect(ch)
+	electionStepDownsCausedByHigherTermTotal.Collect(ch)
+	electionCatchUpsTotal.Collect(ch)
+	electionCatchUpResultsTotal.Collect(ch)
+	electionAverageCatchUpOps.Collect(ch)
+}
+
+// Describe describes the metrics for prometheus
+func (stats *ElectionMetrics) Describe(ch chan<- *prometheus.Desc) {
+	electionCalledTotal.Describe(ch)
+	electionSuccessfulTotal.Describe(ch)
+	electionStepDownsCausedByHigherTermTotal.Describe(ch)
+	electionCatchUpsTotal.Describe(ch)
+	electionCatchUpResultsTotal.Describe(ch)
+	electionAverageCatchUpOps.Describe(ch)
+}
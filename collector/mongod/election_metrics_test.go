@@ -0,0 +1,69 @@
+package collector_mongod
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// counterValue returns the current value of a counter
+func counterValue(t *testing.T, counter prometheus.Counter) float64 {
+	metric := &dto.Metric{}
+	if err := counter.Write(metric); err != nil {
+		t.Fatal(err)
+	}
+	return metric.GetCounter().GetValue()
+}
+
+func Test_ParserElectionMetrics(t *testing.T) {
+	data, err := bson.Marshal(bson.M{
+		"host": "rs1:27017",
+		"electionMetrics": bson.M{
+			"stepUpCmd":                      bson.M{"called": int64(1), "successful": int64(1)},
+			"priorityTakeover":               bson.M{"called": int64(4), "successful": int64(3)},
+			"electionTimeout":                bson.M{"called": int64(6), "successful": int64(2)},
+			"numStepDownsCausedByHigherTerm": int64(2),
+			"numCatchUps":                    int64(6),
+			"numCatchUpsSucceeded":           int64(3),
+			"numCatchUpsAlreadyCaughtUp":     int64(2),
+			"numCatchUpsTimedOut":            int64(1),
+			"averageCatchUpOps":              1.5,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serverStatus := &ServerStatus{}
+	loadServerStatusFromBson(data, serverStatus)
+
+	stats := serverStatus.ElectionMetrics
+	if stats == nil {
+		t.Fatal("ElectionMetrics group was not loaded")
+	}
+	if stats.PriorityTakeover == nil || stats.PriorityTakeover.Called != 4 || stats.CatchUpTakeover != nil {
+		t.Errorf("election reasons were not loaded: %+v", stats)
+	}
+
+	ch := make(chan prometheus.Metric, 100)
+	stats.Export(ch)
+	close(ch)
+
+	if called := counterValue(t, electionCalledTotal.WithLabelValues("priority_takeover")); called != 4 {
+		t.Errorf("Expected 4 priority takeovers called, got %v", called)
+	}
+	if successful := counterValue(t, electionSuccessfulTotal.WithLabelValues("election_timeout")); successful != 2 {
+		t.Errorf("Expected 2 successful election timeouts, got %v", successful)
+	}
+	if catchUps := counterValue(t, electionCatchUpsTotal); catchUps != 6 {
+		t.Errorf("Expected 6 catchups, got %v", catchUps)
+	}
+	if timedOut := counterValue(t, electionCatchUpResultsTotal.WithLabelValues("timed_out")); timedOut != 1 {
+		t.Errorf("Expected 1 timed out catchup, got %v", timedOut)
+	}
+	if stepDowns := counterValue(t, electionStepDownsCausedByHigherTermTotal); stepDowns != 2 {
+		t.Errorf("Expected 2 step downs caused by a higher term, got %v", stepDowns)
+	}
+}
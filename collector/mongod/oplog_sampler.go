@@ -0,0 +1,253 @@
+package collector_mongod
+
+import (
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+var (
+	oplogEntriesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "oplog",
+		Name:      "entries_total",
+		Help:      "The total number of oplog entries read by the oplog sampler per namespace and operation type, the namespaces past the configured limit are counted as ns \"other\"",
+	}, []string{"ns", "op"})
+	oplogEntryBytesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "oplog",
+		Name:      "entry_bytes_total",
+		Help:      "The total size in bytes of the oplog entries read by the oplog sampler per namespace and operation type, the namespaces past the configured limit are counted as ns \"other\"",
+	}, []string{"ns", "op"})
+	oplogEntrySizeBytes = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "oplog",
+		Name:      "entry_size_bytes",
+		Help:      "The size distribution of the oplog entries read by the oplog sampler per operation type",
+		Buckets:   prometheus.ExponentialBuckets(64, 4, 8),
+	}, []string{"op"})
+	oplogSamplerResetsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "oplog_sampler",
+		Name:      "resets_total",
+		Help:      "The total number of times the oplog sampler lost its position because of an oplog rollover or a rollback",
+	})
+	oplogSamplerBudgetExhaustedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "oplog_sampler",
+		Name:      "budget_exhausted_total",
+		Help:      "The total number of scrapes where the oplog sampler stopped on its document or time budget before catching up",
+	})
+)
+
+const (
+	// oplogOtherNs is the ns label value of the namespaces past the limit
+	oplogOtherNs = "other"
+)
+
+var (
+	// the ts of the last oplog entry read by the sampler and the ns label
+	// values exported so far, kept across scrapes
+	oplogSamplerLastTs     bson.MongoTimestamp
+	oplogSamplerNamespaces = make(map[string]bool)
+	oplogSamplerLastLock   sync.Mutex
+)
+
+// OplogSamplerOpts limits the work done by the oplog sampler on each scrape,
+// MaxDocs and MaxTime must be greater than 0. MaxNamespaces limits the number
+// of ns label values, <= 0 keeps all namespaces.
+type OplogSamplerOpts struct {
+	MaxDocs       int
+	MaxTime       time.Duration
+	MaxNamespaces int
+}
+
+// OplogSampleKey is the namespace and operation type of an oplog entry
+type OplogSampleKey struct {
+	Ns string
+	Op string
+}
+
+// OplogSampleCount aggregates the oplog entries of a namespace and operation type
+type OplogSampleCount struct {
+	Entries float64
+	Bytes   float64
+}
+
+// OplogSample keeps the oplog entries read since the previous scrape
+type OplogSample struct {
+	Counts          map[OplogSampleKey]*OplogSampleCount
+	Sizes           map[string][]float64
+	Reset           bool
+	BudgetExhausted bool
+
+	// the ns label values exported so far, see limitOplogNs
+	namespaces map[string]bool
+}
+
+type oplogSampleEntry struct {
+	Timestamp bson.MongoTimestamp `bson:"ts"`
+	Ns        string              `bson:"ns"`
+	Op        string              `bson:"op"`
+}
+
+func (sample *OplogSample) add(entry *oplogSampleEntry, size float64) {
+	key := OplogSampleKey{Ns: entry.Ns, Op: entry.Op}
+	count, ok := sample.Counts[key]
+	if !ok {
+		count = &OplogSampleCount{}
+		sample.Counts[key] = count
+	}
+	count.Entries++
+	count.Bytes += size
+	sample.Sizes[entry.Op] = append(sample.Sizes[entry.Op], size)
+}
+
+// Export exports the data to prometheus.
+func (sample *OplogSample) Export(ch chan<- prometheus.Metric) {
+	for key, count := range sample.Counts {
+		oplogEntriesTotal.WithLabelValues(key.Ns, key.Op).Add(count.Entries)
+		oplogEntryBytesTotal.WithLabelValues(key.Ns, key.Op).Add(count.Bytes)
+	}
+	for op, sizes := range sample.Sizes {
+		for _, size := range sizes {
+			oplogEntrySizeBytes.WithLabelValues(op).Observe(size)
+		}
+	}
+	if sample.Reset {
+		oplogSamplerResetsTotal.Inc()
+	}
+	if sample.BudgetExhausted {
+		oplogSamplerBudgetExhaustedTotal.Inc()
+	}
+
+	oplogEntriesTotal.Collect(ch)
+	oplogEntryBytesTotal.Collect(ch)
+	oplogEntrySizeBytes.Collect(ch)
+	oplogSamplerResetsTotal.Collect(ch)
+	oplogSamplerBudgetExhaustedTotal.Collect(ch)
+}
+
+// Describe describes the metrics for prometheus
+func (sample *OplogSample) Describe(ch chan<- *prometheus.Desc) {
+	oplogEntriesTotal.Describe(ch)
+	oplogEntryBytesTotal.Describe(ch)
+	oplogEntrySizeBytes.Describe(ch)
+	oplogSamplerResetsTotal.Describe(ch)
+	oplogSamplerBudgetExhaustedTotal.Describe(ch)
+}
+
+// limitOplogNs returns the ns label value of a namespace: the first
+// maxNamespaces namespaces seen keep their own label and the later ones are
+// counted as oplogOtherNs, so that the label values of the counters stay the
+// same across scrapes. maxNamespaces <= 0 keeps all namespaces.
+func limitOplogNs(seen map[string]bool, ns string, maxNamespaces int) string {
+	if seen[ns] {
+		return ns
+	}
+	if maxNamespaces > 0 && len(seen) >= maxNamespaces {
+		return oplogOtherNs
+	}
+	seen[ns] = true
+	return ns
+}
+
+// oplogIter is the part of *mgo.Iter used by the oplog sampler
+type oplogIter interface {
+	Next(result interface{}) bool
+	Close() error
+}
+
+// oplogSamplerStart decides where the sampler resumes reading from the ts of
+// the last entry read and the current head and tail of the oplog. It returns
+// the ts to read after, whether entries should be read on this scrape and
+// whether the sampler lost its position.
+func oplogSamplerStart(lastTs, head, tail bson.MongoTimestamp) (bson.MongoTimestamp, bool, bool) {
+	switch {
+	case lastTs == 0:
+		// the first call only records the head
+		return head, false, false
+	case lastTs > head:
+		// the entries after the last seen ts were rolled back
+		return head, false, true
+	case lastTs < tail:
+		// the oplog rolled over past the last seen ts, resume from the tail
+		return lastTs, true, true
+	}
+	return lastTs, true, false
+}
+
+// read adds the entries of iter to the sample until iter is exhausted or the
+// budget runs out, and returns the ts of the last entry read
+func (sample *OplogSample) read(iter oplogIter, lastTs, head bson.MongoTimestamp, opts OplogSamplerOpts) bson.MongoTimestamp {
+	deadline := time.Now().Add(opts.MaxTime)
+	docs := 0
+	var raw bson.Raw
+	for iter.Next(&raw) {
+		entry := &oplogSampleEntry{}
+		if err := raw.Unmarshal(entry); err != nil {
+			glog.Errorf("Failed to decode oplog entry: %s", err)
+			continue
+		}
+		size := float64(len(raw.Data))
+		entry.Ns = limitOplogNs(sample.namespaces, entry.Ns, opts.MaxNamespaces)
+		sample.add(entry, size)
+		lastTs = entry.Timestamp
+
+		docs++
+		if docs >= opts.MaxDocs || time.Now().After(deadline) {
+			sample.BudgetExhausted = lastTs < head
+			break
+		}
+	}
+	if err := iter.Close(); err != nil {
+		// the cursor is lost when the capped collection overwrites its position,
+		// the next scrape resumes from the last entry read
+		glog.Errorf("Oplog sampler cursor was reset: %s", err)
+		sample.Reset = true
+	}
+	return lastTs
+}
+
+// GetOplogSample reads the oplog entries written since the previous scrape in
+// $natural order, stopping when the document or time budget is exhausted. The
+// first call only records the current head of the oplog. The oplog is polled
+// with a ts query on every scrape, no tailable cursor is kept between scrapes.
+func GetOplogSample(session *mgo.Session, opts OplogSamplerOpts) *OplogSample {
+	oplogSamplerLastLock.Lock()
+	defer oplogSamplerLastLock.Unlock()
+
+	sample := &OplogSample{
+		Counts: make(map[OplogSampleKey]*OplogSampleCount),
+		Sizes:  make(map[string][]float64),
+
+		namespaces: oplogSamplerNamespaces,
+	}
+	oplog := session.DB("local").C("oplog.rs")
+
+	var head, tail oplogSampleEntry
+	if err := oplog.Find(nil).Sort("-$natural").Limit(1).One(&head); err != nil {
+		glog.Errorf("Failed to get the oplog head for the oplog sampler: %s", err)
+		return nil
+	}
+	if err := oplog.Find(nil).Sort("$natural").Limit(1).One(&tail); err != nil {
+		glog.Errorf("Failed to get the oplog tail for the oplog sampler: %s", err)
+		return nil
+	}
+
+	start, read, reset := oplogSamplerStart(oplogSamplerLastTs, head.Timestamp, tail.Timestamp)
+	oplogSamplerLastTs = start
+	sample.Reset = reset
+	if !read {
+		return sample
+	}
+
+	iter := oplog.Find(bson.M{"ts": bson.M{"$gt": start}}).Sort("$natural").LogReplay().Batch(1000).Iter()
+	oplogSamplerLastTs = sample.read(iter, start, head.Timestamp, opts)
+
+	return sample
+}
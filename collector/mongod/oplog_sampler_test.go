@@ -0,0 +1,129 @@
+package collector_mongod
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+// fakeOplogIter returns the given oplog entries as raw documents
+type fakeOplogIter struct {
+	entries  []oplogSampleEntry
+	closeErr error
+}
+
+func (iter *fakeOplogIter) Next(result interface{}) bool {
+	if len(iter.entries) == 0 {
+		return false
+	}
+	data, err := bson.Marshal(iter.entries[0])
+	if err != nil {
+		panic(err)
+	}
+	iter.entries = iter.entries[1:]
+	*result.(*bson.Raw) = bson.Raw{Kind: 3, Data: data}
+	return true
+}
+
+func (iter *fakeOplogIter) Close() error {
+	return iter.closeErr
+}
+
+func newTestOplogSample() *OplogSample {
+	return &OplogSample{
+		Counts: make(map[OplogSampleKey]*OplogSampleCount),
+		Sizes:  make(map[string][]float64),
+
+		namespaces: make(map[string]bool),
+	}
+}
+
+func Test_OplogSamplerStart(t *testing.T) {
+	tests := []struct {
+		name               string
+		lastTs, head, tail bson.MongoTimestamp
+		start              bson.MongoTimestamp
+		read, reset        bool
+	}{
+		{name: "first call", lastTs: 0, head: 100, tail: 10, start: 100, read: false, reset: false},
+		{name: "caught up", lastTs: 100, head: 100, tail: 10, start: 100, read: true, reset: false},
+		{name: "behind", lastTs: 50, head: 100, tail: 10, start: 50, read: true, reset: false},
+		{name: "rollback", lastTs: 120, head: 100, tail: 10, start: 100, read: false, reset: true},
+		{name: "rollover", lastTs: 5, head: 100, tail: 10, start: 5, read: true, reset: true},
+	}
+	for _, test := range tests {
+		start, read, reset := oplogSamplerStart(test.lastTs, test.head, test.tail)
+		if start != test.start || read != test.read || reset != test.reset {
+			t.Errorf("%s: got start %d, read %v, reset %v", test.name, start, read, reset)
+		}
+	}
+}
+
+func Test_OplogSampleRead(t *testing.T) {
+	entries := []oplogSampleEntry{
+		{Timestamp: 101, Ns: "test.a", Op: "i"},
+		{Timestamp: 102, Ns: "test.a", Op: "u"},
+		{Timestamp: 103, Ns: "test.b", Op: "i"},
+	}
+
+	unbounded := OplogSamplerOpts{MaxDocs: 100, MaxTime: time.Minute}
+	sample := newTestOplogSample()
+	lastTs := sample.read(&fakeOplogIter{entries: entries}, 100, 103, unbounded)
+	if lastTs != 103 || sample.BudgetExhausted || sample.Reset {
+		t.Errorf("unexpected unbounded read: lastTs %d, %+v", lastTs, sample)
+	}
+	if count := sample.Counts[OplogSampleKey{Ns: "test.a", Op: "i"}]; count == nil || count.Entries != 1 || count.Bytes == 0 {
+		t.Errorf("unexpected count %+v", count)
+	}
+	if len(sample.Sizes["i"]) != 2 {
+		t.Errorf("unexpected insert sizes %v", sample.Sizes["i"])
+	}
+
+	sample = newTestOplogSample()
+	lastTs = sample.read(&fakeOplogIter{entries: entries}, 100, 103, OplogSamplerOpts{MaxDocs: 2, MaxTime: time.Minute})
+	if lastTs != 102 || !sample.BudgetExhausted {
+		t.Errorf("the document budget was not applied: lastTs %d, %+v", lastTs, sample)
+	}
+
+	sample = newTestOplogSample()
+	lastTs = sample.read(&fakeOplogIter{entries: entries}, 100, 103, OplogSamplerOpts{MaxDocs: 3, MaxTime: time.Minute})
+	if lastTs != 103 || sample.BudgetExhausted {
+		t.Errorf("reaching the head on the last allowed document is not an exhausted budget: lastTs %d, %+v", lastTs, sample)
+	}
+
+	sample = newTestOplogSample()
+	lastTs = sample.read(&fakeOplogIter{entries: entries[:1], closeErr: errors.New("CappedPositionLost")}, 100, 103, unbounded)
+	if lastTs != 101 || !sample.Reset {
+		t.Errorf("a lost cursor was not reported as a reset: lastTs %d, %+v", lastTs, sample)
+	}
+}
+
+func Test_OplogSampleNamespaceLimit(t *testing.T) {
+	entries := []oplogSampleEntry{
+		{Timestamp: 101, Ns: "test.a", Op: "i"},
+		{Timestamp: 102, Ns: "test.b", Op: "i"},
+		{Timestamp: 103, Ns: "test.c", Op: "i"},
+		{Timestamp: 104, Ns: "test.a", Op: "u"},
+	}
+	opts := OplogSamplerOpts{MaxDocs: 100, MaxTime: time.Minute, MaxNamespaces: 2}
+
+	sample := newTestOplogSample()
+	sample.read(&fakeOplogIter{entries: entries}, 100, 104, opts)
+	if count := sample.Counts[OplogSampleKey{Ns: oplogOtherNs, Op: "i"}]; count == nil || count.Entries != 1 {
+		t.Errorf("the namespace past the limit was not counted as %q: %+v", oplogOtherNs, sample.Counts)
+	}
+	if count := sample.Counts[OplogSampleKey{Ns: "test.a", Op: "u"}]; count == nil || count.Entries != 1 {
+		t.Errorf("a namespace within the limit lost its label: %+v", sample.Counts)
+	}
+
+	// the namespaces seen on a previous scrape keep their label
+	namespaces := sample.namespaces
+	sample = newTestOplogSample()
+	sample.namespaces = namespaces
+	sample.read(&fakeOplogIter{entries: []oplogSampleEntry{{Timestamp: 105, Ns: "test.c", Op: "d"}, {Timestamp: 106, Ns: "test.b", Op: "d"}}}, 104, 106, opts)
+	if len(sample.Counts) != 2 || sample.Counts[OplogSampleKey{Ns: "test.b", Op: "d"}] == nil || sample.Counts[OplogSampleKey{Ns: oplogOtherNs, Op: "d"}] == nil {
+		t.Errorf("unexpected namespaces on the next scrape: %+v", sample.Counts)
+	}
+}
@@ -1,6 +1,9 @@
 package collector_mongod
 
 import (
+	"sync"
+	"time"
+
 	"github.com/golang/glog"
 	"github.com/prometheus/client_golang/prometheus"
 	"gopkg.in/mgo.v2"
@@ -32,12 +35,37 @@ var (
 		Name:		"size_bytes",
 		Help:		"Size of oplog in bytes",
 	}, []string{"type"})
+	oplogStatusWindowSeconds = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace:	Namespace,
+		Subsystem:	"replset_oplog",
+		Name:		"window_seconds",
+		Help:		"The time range in seconds between the oldest and the newest change in the oplog",
+	})
+	oplogStatusBytesPerSecond = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace:	Namespace,
+		Subsystem:	"replset_oplog",
+		Name:		"bytes_per_second",
+		Help:		"The estimated rate in bytes per second the oplog is written at, sampled between scrapes",
+	})
+	oplogStatusProjectedWindowSeconds = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace:	Namespace,
+		Subsystem:	"replset_oplog",
+		Name:		"projected_window_seconds",
+		Help:		"The seconds of history the oplog can hold at the current write rate",
+	})
+)
+
+var (
+	// the previous oplog sample, to estimate the write rate across scrapes
+	lastOplogSample     *oplogSample
+	lastOplogSampleLock sync.Mutex
 )
 
 type OplogCollectionStats struct {
 	Count		float64	`bson:"count"`
 	Size		float64	`bson:"size"`
 	StorageSize	float64 `bson:"storageSize"`
+	MaxSize		float64	`bson:"maxSize"`
 }
 
 type OplogTimestamps struct {
@@ -48,6 +76,31 @@ type OplogTimestamps struct {
 type OplogStatus struct {
 	OplogTimestamps	*OplogTimestamps
 	CollectionStats	*OplogCollectionStats
+	BytesPerSecond	*float64
+}
+
+type oplogSample struct {
+	Time	time.Time
+	Size	float64
+	Tail	float64
+	Head	float64
+}
+
+// estimateOplogBytesPerSecond estimates the oplog write rate between two samples.
+// Once the oplog is full its size stays constant, so the bytes written are the
+// size growth plus the share of the previous window evicted by the tail moving.
+func estimateOplogBytesPerSecond(prev *oplogSample, cur *oplogSample) (float64, bool) {
+	elapsed := cur.Time.Sub(prev.Time).Seconds()
+	prevWindow := prev.Head - prev.Tail
+	if elapsed <= 0 || prevWindow <= 0 || cur.Tail < prev.Tail {
+		return 0, false
+	}
+	evicted := prev.Size * (cur.Tail - prev.Tail) / prevWindow
+	written := cur.Size - prev.Size + evicted
+	if written < 0 {
+		return 0, false
+	}
+	return written / elapsed, true
 }
 
 // there's gotta be a better way to do this, but it works for now :/
@@ -109,12 +162,22 @@ func (status *OplogStatus) Export(ch chan<- prometheus.Metric) {
 	if status.OplogTimestamps != nil {
 		oplogStatusHeadTimestamp.Set(status.OplogTimestamps.Head)
 		oplogStatusTailTimestamp.Set(status.OplogTimestamps.Tail)
+		oplogStatusWindowSeconds.Set(status.OplogTimestamps.Head - status.OplogTimestamps.Tail)
+	}
+	if status.BytesPerSecond != nil {
+		oplogStatusBytesPerSecond.Set(*status.BytesPerSecond)
+		oplogStatusBytesPerSecond.Collect(ch)
+		if *status.BytesPerSecond > 0 && status.CollectionStats != nil && status.CollectionStats.MaxSize > 0 {
+			oplogStatusProjectedWindowSeconds.Set(status.CollectionStats.MaxSize / *status.BytesPerSecond)
+			oplogStatusProjectedWindowSeconds.Collect(ch)
+		}
 	}
 
 	oplogStatusCount.Collect(ch)
 	oplogStatusHeadTimestamp.Collect(ch)
 	oplogStatusTailTimestamp.Collect(ch)
 	oplogStatusSizeBytes.Collect(ch)
+	oplogStatusWindowSeconds.Collect(ch)
 }
 
 func (status *OplogStatus) Describe(ch chan<- *prometheus.Desc) {
@@ -122,15 +185,39 @@ func (status *OplogStatus) Describe(ch chan<- *prometheus.Desc) {
 	oplogStatusHeadTimestamp.Describe(ch)
 	oplogStatusTailTimestamp.Describe(ch)
 	oplogStatusSizeBytes.Describe(ch)
+	oplogStatusWindowSeconds.Describe(ch)
+	oplogStatusBytesPerSecond.Describe(ch)
+	oplogStatusProjectedWindowSeconds.Describe(ch)
 }
 
 func GetOplogStatus(session *mgo.Session) *OplogStatus {
-	collectionStats, err := GetOplogCollectionStats(session)
+	collectionStats, statsErr := GetOplogCollectionStats(session)
 	oplogTimestamps, err := GetOplogTimestamps(session)
 	if err != nil {
 		glog.Error("Failed to get oplog status.")
 		return nil
 	}
 
-	return &OplogStatus{CollectionStats:collectionStats,OplogTimestamps:oplogTimestamps}
+	status := &OplogStatus{CollectionStats:collectionStats,OplogTimestamps:oplogTimestamps}
+	if statsErr != nil {
+		glog.Error("Failed to get oplog collection stats.")
+		return status
+	}
+
+	sample := &oplogSample{
+		Time:	time.Now(),
+		Size:	collectionStats.Size,
+		Tail:	oplogTimestamps.Tail,
+		Head:	oplogTimestamps.Head,
+	}
+	lastOplogSampleLock.Lock()
+	if lastOplogSample != nil {
+		if bytesPerSecond, ok := estimateOplogBytesPerSecond(lastOplogSample, sample); ok {
+			status.BytesPerSecond = &bytesPerSecond
+		}
+	}
+	lastOplogSample = sample
+	lastOplogSampleLock.Unlock()
+
+	return status
 }
@@ -0,0 +1,29 @@
+package collector_mongod
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_EstimateOplogBytesPerSecond(t *testing.T) {
+	now := time.Now()
+	prev := &oplogSample{Time: now, Size: 1000, Tail: 0, Head: 100}
+
+	// oplog is full: the tail moved 10% of the window in 10 seconds
+	cur := &oplogSample{Time: now.Add(10 * time.Second), Size: 1000, Tail: 10, Head: 110}
+	if rate, ok := estimateOplogBytesPerSecond(prev, cur); !ok || rate != 10 {
+		t.Errorf("unexpected rate for a full oplog: %v", rate)
+	}
+
+	// oplog is still growing: the tail did not move
+	cur = &oplogSample{Time: now.Add(10 * time.Second), Size: 1500, Tail: 0, Head: 110}
+	if rate, ok := estimateOplogBytesPerSecond(prev, cur); !ok || rate != 50 {
+		t.Errorf("unexpected rate for a growing oplog: %v", rate)
+	}
+
+	// the oplog was resized or the node resynced
+	cur = &oplogSample{Time: now.Add(10 * time.Second), Size: 100, Tail: 0, Head: 110}
+	if _, ok := estimateOplogBytesPerSecond(prev, cur); ok {
+		t.Error("a shrinking oplog should not produce a rate")
+	}
+}
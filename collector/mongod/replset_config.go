@@ -0,0 +1,275 @@
+package collector_mongod
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+var (
+	configVersion = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "config_version",
+		Help:      "The version of the active replica set configuration",
+	}, []string{"set"})
+	configProtocolVersion = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "config_protocol_version",
+		Help:      "The replication protocol version of the replica set configuration",
+	}, []string{"set"})
+	configChainingAllowed = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "config_chaining_allowed",
+		Help:      "Boolean reporting if secondaries may replicate from other secondaries (1 = allowed/0 = not allowed)",
+	}, []string{"set"})
+	configElectionTimeoutMillis = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "config_election_timeout_millis",
+		Help:      "The time limit in milliseconds for detecting when the primary is unreachable",
+	}, []string{"set"})
+	configWriteConcernMajorityJournalDefault = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "config_write_concern_majority_journal_default",
+		Help:      "Boolean reporting if majority write concerns wait for the journal by default (1 = yes/0 = no)",
+	}, []string{"set"})
+	memberConfigInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "member_config_info",
+		Help:      "The configuration flags of the replica set member, the value is always 1",
+	}, []string{"set", "name", "hidden", "arbiter", "build_indexes", "tags"})
+	memberConfigPriority = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "member_config_priority",
+		Help:      "The election priority of the replica set member",
+	}, []string{"set", "name"})
+	memberConfigVotes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "member_config_votes",
+		Help:      "The number of votes the replica set member has in elections",
+	}, []string{"set", "name"})
+	memberConfigDelaySeconds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "member_config_delay_seconds",
+		Help:      "The configured replication delay of the replica set member (slaveDelay or secondaryDelaySecs)",
+	}, []string{"set", "name"})
+	memberConfigVersionMismatch = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "member_config_version_mismatch",
+		Help:      "Boolean reporting if the configVersion the member reports in replSetGetStatus differs from the active configuration (1 = differs/0 = matches)",
+	}, []string{"set", "name"})
+	memberDelayDeviationSeconds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "member_delay_deviation_seconds",
+		Help:      "The replication lag of the member minus its configured delay, a delayed member that keeps up with its delay stays close to 0",
+	}, []string{"set", "name"})
+)
+
+// ReplSetConfigMember represents an array element of ReplSetConfig.Members
+type ReplSetConfigMember struct {
+	Id                 int32             `bson:"_id"`
+	Host               string            `bson:"host"`
+	ArbiterOnly        bool              `bson:"arbiterOnly"`
+	BuildIndexes       *bool             `bson:"buildIndexes,omitempty"`
+	Hidden             bool              `bson:"hidden"`
+	Priority           float64           `bson:"priority"`
+	Tags               map[string]string `bson:"tags,omitempty"`
+	SlaveDelay         *float64          `bson:"slaveDelay,omitempty"`
+	SecondaryDelaySecs *float64          `bson:"secondaryDelaySecs,omitempty"`
+	Votes              float64           `bson:"votes"`
+}
+
+// DelaySeconds returns the configured replication delay, slaveDelay was
+// renamed to secondaryDelaySecs in version 5.0
+func (member *ReplSetConfigMember) DelaySeconds() float64 {
+	if member.SecondaryDelaySecs != nil {
+		return *member.SecondaryDelaySecs
+	}
+	if member.SlaveDelay != nil {
+		return *member.SlaveDelay
+	}
+	return 0
+}
+
+// ReplSetConfigSettings represents the settings document of ReplSetConfig
+type ReplSetConfigSettings struct {
+	ChainingAllowed       *bool    `bson:"chainingAllowed,omitempty"`
+	ElectionTimeoutMillis *float64 `bson:"electionTimeoutMillis,omitempty"`
+}
+
+// ReplSetConfig keeps the data returned by the GetReplSetConfig method
+type ReplSetConfig struct {
+	Set                                string                 `bson:"_id"`
+	Version                            int32                  `bson:"version"`
+	ProtocolVersion                    *float64               `bson:"protocolVersion,omitempty"`
+	WriteConcernMajorityJournalDefault *bool                  `bson:"writeConcernMajorityJournalDefault,omitempty"`
+	Members                            []ReplSetConfigMember  `bson:"members"`
+	Settings                           *ReplSetConfigSettings `bson:"settings,omitempty"`
+
+	// Status is joined with the config to compare the members configVersion
+	// and replication lag with the active config
+	Status *ReplSetStatus `bson:"-"`
+}
+
+func boolLabel(value bool) string {
+	return strconv.FormatBool(value)
+}
+
+func boolValue(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+// tagsLabel flattens the member tags to a sorted "key=value,..." label value
+func tagsLabel(tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for key, value := range tags {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// delayDeviations joins the member optimes of Status with the configured
+// delays and returns the replication lag minus the delay per member
+func (config *ReplSetConfig) delayDeviations() map[string]float64 {
+	deviations := make(map[string]float64)
+	referenceOptimeDate, ok := config.Status.referenceOptimeDate()
+	if !ok {
+		return deviations
+	}
+
+	delays := make(map[string]float64)
+	for _, member := range config.Members {
+		if !member.ArbiterOnly {
+			delays[member.Host] = member.DelaySeconds()
+		}
+	}
+	for _, member := range config.Status.Members {
+		delay, ok := delays[member.Name]
+		if !ok || member.OptimeDate.IsZero() {
+			continue
+		}
+		deviations[member.Name] = referenceOptimeDate.Sub(member.OptimeDate).Seconds() - delay
+	}
+	return deviations
+}
+
+// Export exports the replSetGetConfig data to be consumed by prometheus
+func (config *ReplSetConfig) Export(ch chan<- prometheus.Metric) {
+	configVersion.Reset()
+	configProtocolVersion.Reset()
+	configChainingAllowed.Reset()
+	configElectionTimeoutMillis.Reset()
+	configWriteConcernMajorityJournalDefault.Reset()
+	memberConfigInfo.Reset()
+	memberConfigPriority.Reset()
+	memberConfigVotes.Reset()
+	memberConfigDelaySeconds.Reset()
+	memberConfigVersionMismatch.Reset()
+	memberDelayDeviationSeconds.Reset()
+
+	configVersion.WithLabelValues(config.Set).Set(float64(config.Version))
+
+	// protocolVersion is new in version 3.2, members use protocol version 0 without it
+	var protocolVersion float64
+	if config.ProtocolVersion != nil {
+		protocolVersion = *config.ProtocolVersion
+	}
+	configProtocolVersion.WithLabelValues(config.Set).Set(protocolVersion)
+
+	// new in version 3.4
+	if config.WriteConcernMajorityJournalDefault != nil {
+		configWriteConcernMajorityJournalDefault.WithLabelValues(config.Set).Set(boolValue(*config.WriteConcernMajorityJournalDefault))
+	}
+
+	chainingAllowed := true
+	if config.Settings != nil {
+		if config.Settings.ChainingAllowed != nil {
+			chainingAllowed = *config.Settings.ChainingAllowed
+		}
+		if config.Settings.ElectionTimeoutMillis != nil {
+			configElectionTimeoutMillis.WithLabelValues(config.Set).Set(*config.Settings.ElectionTimeoutMillis)
+		}
+	}
+	configChainingAllowed.WithLabelValues(config.Set).Set(boolValue(chainingAllowed))
+
+	for _, member := range config.Members {
+		buildIndexes := member.BuildIndexes == nil || *member.BuildIndexes
+		memberConfigInfo.WithLabelValues(config.Set, member.Host, boolLabel(member.Hidden), boolLabel(member.ArbiterOnly), boolLabel(buildIndexes), tagsLabel(member.Tags)).Set(1)
+		memberConfigPriority.WithLabelValues(config.Set, member.Host).Set(member.Priority)
+		memberConfigVotes.WithLabelValues(config.Set, member.Host).Set(member.Votes)
+		memberConfigDelaySeconds.WithLabelValues(config.Set, member.Host).Set(member.DelaySeconds())
+	}
+
+	if config.Status != nil {
+		for name, deviation := range config.delayDeviations() {
+			memberDelayDeviationSeconds.WithLabelValues(config.Set, name).Set(deviation)
+		}
+		for _, member := range config.Status.Members {
+			// ReplSetStatus.Member.ConfigVersion is not available on all versions
+			if member.ConfigVersion == nil {
+				continue
+			}
+			mismatch := *member.ConfigVersion != config.Version
+			memberConfigVersionMismatch.WithLabelValues(config.Set, member.Name).Set(boolValue(mismatch))
+		}
+	}
+
+	configVersion.Collect(ch)
+	configProtocolVersion.Collect(ch)
+	configChainingAllowed.Collect(ch)
+	configElectionTimeoutMillis.Collect(ch)
+	configWriteConcernMajorityJournalDefault.Collect(ch)
+	memberConfigInfo.Collect(ch)
+	memberConfigPriority.Collect(ch)
+	memberConfigVotes.Collect(ch)
+	memberConfigDelaySeconds.Collect(ch)
+	memberConfigVersionMismatch.Collect(ch)
+	memberDelayDeviationSeconds.Collect(ch)
+}
+
+// Describe describes the replSetGetConfig metrics for prometheus
+func (config *ReplSetConfig) Describe(ch chan<- *prometheus.Desc) {
+	configVersion.Describe(ch)
+	configProtocolVersion.Describe(ch)
+	configChainingAllowed.Describe(ch)
+	configElectionTimeoutMillis.Describe(ch)
+	configWriteConcernMajorityJournalDefault.Describe(ch)
+	memberConfigInfo.Describe(ch)
+	memberConfigPriority.Describe(ch)
+	memberConfigVotes.Describe(ch)
+	memberConfigDelaySeconds.Describe(ch)
+	memberConfigVersionMismatch.Describe(ch)
+	memberDelayDeviationSeconds.Describe(ch)
+}
+
+// GetReplSetConfig returns the replica set configuration
+func GetReplSetConfig(session *mgo.Session) *ReplSetConfig {
+	result := struct {
+		Config *ReplSetConfig `bson:"config"`
+	}{}
+	err := session.DB("admin").Run(bson.D{{"replSetGetConfig", 1}}, &result)
+	if err != nil {
+		glog.Error("Failed to get replSet config.")
+		return nil
+	}
+	return result.Config
+}
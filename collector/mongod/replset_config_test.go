@@ -0,0 +1,33 @@
+package collector_mongod
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_ReplSetConfigDelayDeviations(t *testing.T) {
+	now := time.Now()
+	delay := float64(3600)
+	config := &ReplSetConfig{
+		Members: []ReplSetConfigMember{
+			{Host: "a:27017"},
+			{Host: "b:27017", Hidden: true, SlaveDelay: &delay},
+			{Host: "c:27017", ArbiterOnly: true},
+		},
+		Status: &ReplSetStatus{
+			Members: []Member{
+				{Name: "a:27017", State: 1, OptimeDate: now},
+				{Name: "b:27017", State: 2, OptimeDate: now.Add(-3610 * time.Second)},
+				{Name: "c:27017", State: 7},
+			},
+		},
+	}
+
+	deviations := config.delayDeviations()
+	if deviations["a:27017"] != 0 || deviations["b:27017"] != 10 {
+		t.Errorf("unexpected delay deviations: %v", deviations)
+	}
+	if _, ok := deviations["c:27017"]; ok {
+		t.Error("arbiters should not have a delay deviation")
+	}
+}
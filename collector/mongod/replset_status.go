@@ -1,12 +1,15 @@
 package collector_mongod
 
 import (
+	"math"
+	"sync"
 	"time"
 
 	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 
 	"github.com/golang/glog"
+	"github.com/percona/mongodb_exporter/shared"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -53,63 +56,249 @@ var (
 		Subsystem: subsystem,
 		Name:      "member_health",
 		Help:      "This field conveys if the member is up (1) or down (0).",
-	}, []string{"set", "name", "state"})
+	}, []string{"set", "name"})
 	memberState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: Namespace,
 		Subsystem: subsystem,
 		Name:      "member_state",
-		Help:      "The value of state is an integer between 0 and 10 that represents the replica state of the member.",
+		Help:      "The replica state of the member as a stateset: 1 for the current state of the member, 0 for every other state.",
 	}, []string{"set", "name", "state"})
 	memberUptime = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Namespace: Namespace,
 		Subsystem: subsystem,
 		Name:      "member_uptime",
 		Help:      "The uptime field holds a value that reflects the number of seconds that this member has been online.",
-	}, []string{"set", "name", "state"})
+	}, []string{"set", "name"})
 	memberOptimeDate = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: Namespace,
 		Subsystem: subsystem,
 		Name:      "member_optime_date",
 		Help:      "The timestamp of the last oplog entry that this member applied.",
-	}, []string{"set", "name", "state"})
+	}, []string{"set", "name"})
 	memberElectionDate = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: Namespace,
 		Subsystem: subsystem,
 		Name:      "member_election_date",
 		Help:      "The timestamp the node was elected as replica leader",
-	}, []string{"set", "name", "state"})
+	}, []string{"set", "name"})
 	memberLastHeartbeat = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: Namespace,
 		Subsystem: subsystem,
 		Name:      "member_last_heartbeat",
 		Help:      "The lastHeartbeat value provides an ISODate formatted date and time of the transmission time of last heartbeat received from this member",
-	}, []string{"set", "name", "state"})
+	}, []string{"set", "name"})
 	memberLastHeartbeatRecv = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: Namespace,
 		Subsystem: subsystem,
 		Name:      "member_last_heartbeat_recv",
 		Help:      "The lastHeartbeatRecv value provides an ISODate formatted date and time that the last heartbeat was received from this member",
-	}, []string{"set", "name", "state"})
+	}, []string{"set", "name"})
 	memberPingMs = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: Namespace,
 		Subsystem: subsystem,
 		Name:      "member_ping_ms",
 		Help:      "The pingMs represents the number of milliseconds (ms) that a round-trip packet takes to travel between the remote member and the local instance.",
-	}, []string{"set", "name", "state"})
+	}, []string{"set", "name"})
 	memberConfigVersion = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: Namespace,
 		Subsystem: subsystem,
 		Name:      "member_config_version",
 		Help:      "The configVersion value is the replica set configuration version.",
-	}, []string{"set", "name", "state"})
+	}, []string{"set", "name"})
 	memberOptime = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: Namespace,
 		Subsystem: subsystem,
 		Name:      "member_optime",
 		Help:      "Information regarding the last operation from the operation log that this member has applied.",
-	}, []string{"set", "name", "state"})
+	}, []string{"set", "name"})
+	memberOptimeTerm = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "member_optime_term",
+		Help:      "The term of the last oplog entry that this member applied (protocol version 1 only).",
+	}, []string{"set", "name"})
+	memberOptimeDurableDate = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "member_optime_durable_date",
+		Help:      "The timestamp of the last oplog entry that this member has written to its journal (new in version 3.4).",
+	}, []string{"set", "name"})
+	memberOptimeDurableTerm = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "member_optime_durable_term",
+		Help:      "The term of the last oplog entry that this member has written to its journal (protocol version 1 only).",
+	}, []string{"set", "name"})
+	optimeTimestamp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "optime_timestamp",
+		Help:      "The timestamp of the last committed, read concern majority, applied and durable optimes of the replica set (new in version 3.4).",
+	}, []string{"set", "type"})
+	optimeTerm = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "optime_term",
+		Help:      "The term of the last committed, read concern majority, applied and durable optimes of the replica set (protocol version 1 only).",
+	}, []string{"set", "type"})
+	memberSyncSource = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "member_sync_source",
+		Help:      "The member this member is replicating from, the value is always 1.",
+	}, []string{"set", "name", "source"})
+	memberChainingDepth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "member_chaining_depth",
+		Help:      "The number of replication hops between the primary and this member (0 = primary/1 = syncing from the primary).",
+	}, []string{"set", "name"})
+	memberHeartbeatError = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "member_heartbeat_error",
+		Help:      "Boolean reporting if the last heartbeat to this member returned a lastHeartbeatMessage (1 = error/0 = ok).",
+	}, []string{"set", "name"})
+	initialSyncAttempts = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "initial_sync_attempts",
+		Help:      "The number of failed initial sync attempts and the maximum number of attempts before the initial sync is aborted.",
+	}, []string{"set", "type"})
+	initialSyncFetchedMissingDocs = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "initial_sync_fetched_missing_docs",
+		Help:      "The number of documents fetched from the sync source while applying the oplog during the initial sync.",
+	}, []string{"set"})
+	initialSyncAppliedOps = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "initial_sync_applied_ops",
+		Help:      "The number of oplog entries applied during the initial sync.",
+	}, []string{"set"})
+	initialSyncElapsedSeconds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "initial_sync_elapsed_seconds",
+		Help:      "The time in seconds elapsed since the start of the initial sync.",
+	}, []string{"set"})
+	initialSyncDatabases = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "initial_sync_databases",
+		Help:      "The number of databases cloned and to be cloned by the initial sync.",
+	}, []string{"set", "type"})
+	initialSyncCollectionDocuments = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "initial_sync_collection_documents",
+		Help:      "The number of documents to copy and copied per collection by the initial sync.",
+	}, []string{"set", "ns", "type"})
+	initialSyncCollectionElapsedSeconds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "initial_sync_collection_elapsed_seconds",
+		Help:      "The time in seconds spent cloning each collection by the initial sync.",
+	}, []string{"set", "ns"})
+	rollbackId = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "rollback_id",
+		Help:      "The rollback identifier (rbid) of this member, it changes on every rollback and, before version 3.6, on every restart.",
+	}, []string{"set"})
+	rollbacksTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "rollbacks_total",
+		Help:      "The number of rollbacks of this member observed by the exporter through changes of its rbid, changes caused by a restart are ignored.",
+	}, []string{"set"})
+	primaryChangesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "primary_changes_total",
+		Help:      "The number of primary changes (a new primary or a new term) observed by the exporter since it started.",
+	}, []string{"set"})
+	lastPrimaryChangeTimestamp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "last_primary_change_timestamp",
+		Help:      "The unix timestamp of the last primary change observed by the exporter.",
+	}, []string{"set"})
+	memberReplicationLag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "member_replication_lag_seconds",
+		Help:      "The number of seconds the member's last applied operation is behind the primary's, or behind the most recent optime of the set when there is no primary.",
+	}, []string{"set", "name"})
+	memberMajorityCommitLag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: subsystem,
+		Name:      "member_majority_commit_lag_seconds",
+		Help:      "The number of seconds the majority commit point (optimes.lastCommittedOpTime) is behind the member's last applied operation.",
+	}, []string{"set", "name"})
+)
+
+var (
+	// the primary seen on the previous scrape per replica set
+	lastPrimaries     = make(map[string]primaryObservation)
+	lastPrimariesLock sync.Mutex
+)
+
+var (
+	// the rbid and uptime seen on the previous scrape per replica set
+	lastRBIDs     = make(map[string]rbidObservation)
+	lastRBIDsLock sync.Mutex
+)
+
+const (
+	// rbidUptimeSlack absorbs the rounding of the uptime to whole seconds when
+	// it is compared with the time between two scrapes
+	rbidUptimeSlack = 5 * time.Second
 )
 
+type rbidObservation struct {
+	RBID   int32
+	Uptime float64
+	Date   time.Time
+}
+
+// restarted returns true if the member restarted between the previous and the
+// current observation, its uptime then grew less than the time in between
+func (current rbidObservation) restarted(previous rbidObservation) bool {
+	elapsed := current.Date.Sub(previous.Date) - rbidUptimeSlack
+	return current.Uptime < previous.Uptime+elapsed.Seconds()
+}
+
+// observeRBID records the rbid of a set and reports whether it changed since
+// the previous scrape, which means the member went through a rollback. Before
+// version 3.6 the rbid is only kept in memory and changes on every restart, a
+// change when the member restarted since the previous scrape is not a rollback.
+func observeRBID(set string, current rbidObservation) bool {
+	lastRBIDsLock.Lock()
+	defer lastRBIDsLock.Unlock()
+
+	previous, ok := lastRBIDs[set]
+	lastRBIDs[set] = current
+	return ok && previous.RBID != current.RBID && !current.restarted(previous)
+}
+
+type primaryObservation struct {
+	Name string
+	Term int32
+}
+
+// observePrimary records the current primary of a set and reports whether it
+// changed since the previous scrape. The first observation is not a change.
+func observePrimary(set string, current primaryObservation) bool {
+	lastPrimariesLock.Lock()
+	defer lastPrimariesLock.Unlock()
+
+	previous, ok := lastPrimaries[set]
+	lastPrimaries[set] = current
+	return ok && (previous.Name != current.Name || current.Term > previous.Term)
+}
+
 // ReplSetStatus keeps the data returned by the GetReplSetStatus method
 type ReplSetStatus struct {
 	Set                     string    `bson:"set"`
@@ -118,26 +307,245 @@ type ReplSetStatus struct {
 	Term                    *int32    `bson:"term,omitempty"`
 	HeartbeatIntervalMillis *float64  `bson:"heartbeatIntervalMillis,omitempty"`
 	Members                 []Member  `bson:"members"`
+	Optimes                 *Optimes  `bson:"optimes,omitempty"`
+
+	InitialSyncStatus *InitialSyncStatus `bson:"initialSyncStatus,omitempty"`
+
+	// RBID is filled from replSetGetRBID, see GetReplSetStatus
+	RBID *int32 `bson:"-"`
+}
+
+// InitialSyncCollection represents the clone progress of a collection
+type InitialSyncCollection struct {
+	DocumentsToCopy float64 `bson:"documentsToCopy"`
+	DocumentsCopied float64 `bson:"documentsCopied"`
+	ElapsedMillis   float64 `bson:"elapsedMillis"`
+}
+
+// InitialSyncStatus represents the initialSyncStatus document of ReplSetStatus,
+// returned with initialSync: 1 (new in version 3.4)
+type InitialSyncStatus struct {
+	FailedInitialSyncAttempts     float64             `bson:"failedInitialSyncAttempts"`
+	MaxFailedInitialSyncAttempts  float64             `bson:"maxFailedInitialSyncAttempts"`
+	InitialSyncStart              *time.Time          `bson:"initialSyncStart,omitempty"`
+	TotalInitialSyncElapsedMillis *float64            `bson:"totalInitialSyncElapsedMillis,omitempty"`
+	FetchedMissingDocs            float64             `bson:"fetchedMissingDocs"`
+	AppliedOps                    float64             `bson:"appliedOps"`
+	Databases                     map[string]bson.Raw `bson:"databases,omitempty"`
+}
+
+// clonedDatabases returns the number of databases cloned, the number of
+// databases to clone and the progress of every collection by namespace. The
+// databases document mixes the databasesCloned counter with a sub-document
+// per database, which itself mixes counters with a sub-document per collection.
+func (status *InitialSyncStatus) clonedDatabases() (float64, float64, map[string]*InitialSyncCollection) {
+	var cloned, total float64
+	collections := make(map[string]*InitialSyncCollection)
+	for name, raw := range status.Databases {
+		if name == "databasesCloned" {
+			raw.Unmarshal(&cloned)
+			continue
+		}
+		if raw.Kind != 0x03 {
+			continue
+		}
+		total++
+
+		database := make(map[string]bson.Raw)
+		if err := raw.Unmarshal(&database); err != nil {
+			continue
+		}
+		for ns, collectionRaw := range database {
+			if collectionRaw.Kind != 0x03 {
+				continue
+			}
+			collection := &InitialSyncCollection{}
+			if err := collectionRaw.Unmarshal(collection); err == nil {
+				collections[ns] = collection
+			}
+		}
+	}
+	return cloned, total, collections
+}
+
+// setMetrics sets the initial sync metrics of the replica set
+func (status *InitialSyncStatus) setMetrics(set string, now time.Time) {
+	initialSyncAttempts.WithLabelValues(set, "failed").Set(status.FailedInitialSyncAttempts)
+	initialSyncAttempts.WithLabelValues(set, "max").Set(status.MaxFailedInitialSyncAttempts)
+	initialSyncFetchedMissingDocs.WithLabelValues(set).Set(status.FetchedMissingDocs)
+	initialSyncAppliedOps.WithLabelValues(set).Set(status.AppliedOps)
+	if status.TotalInitialSyncElapsedMillis != nil {
+		initialSyncElapsedSeconds.WithLabelValues(set).Set(*status.TotalInitialSyncElapsedMillis / 1000)
+	} else if status.InitialSyncStart != nil {
+		initialSyncElapsedSeconds.WithLabelValues(set).Set(now.Sub(*status.InitialSyncStart).Seconds())
+	}
+
+	cloned, total, collections := status.clonedDatabases()
+	initialSyncDatabases.WithLabelValues(set, "cloned").Set(cloned)
+	initialSyncDatabases.WithLabelValues(set, "total").Set(total)
+	for ns, collection := range collections {
+		initialSyncCollectionDocuments.WithLabelValues(set, ns, "to_copy").Set(collection.DocumentsToCopy)
+		initialSyncCollectionDocuments.WithLabelValues(set, ns, "copied").Set(collection.DocumentsCopied)
+		initialSyncCollectionElapsedSeconds.WithLabelValues(set, ns).Set(collection.ElapsedMillis / 1000)
+	}
+}
+
+// OpTime represents an optime in both the protocol version 0 shape (a plain
+// timestamp) and the protocol version 1 shape ({ts, t}). Term is nil for PV0.
+type OpTime struct {
+	Timestamp bson.MongoTimestamp
+	Term      *int64
+}
+
+// SetBSON implements bson.Setter to accept both optime shapes
+func (optime *OpTime) SetBSON(raw bson.Raw) error {
+	// 0x11 is the BSON timestamp type used by protocol version 0
+	if raw.Kind == 0x11 {
+		return raw.Unmarshal(&optime.Timestamp)
+	}
+
+	doc := struct {
+		Timestamp bson.MongoTimestamp `bson:"ts"`
+		Term      *int64              `bson:"t,omitempty"`
+	}{}
+	if err := raw.Unmarshal(&doc); err != nil {
+		return err
+	}
+	optime.Timestamp = doc.Timestamp
+	// protocol version 0 members report a term of -1 since 3.2
+	if doc.Term != nil && *doc.Term >= 0 {
+		optime.Term = doc.Term
+	}
+	return nil
+}
+
+// Unix returns the optime timestamp in seconds
+func (optime *OpTime) Unix() float64 {
+	return BsonMongoTimestampToUnix(optime.Timestamp)
+}
+
+// Optimes represents the optimes document of ReplSetStatus (new in version 3.4)
+type Optimes struct {
+	LastCommittedOpTime       *OpTime `bson:"lastCommittedOpTime,omitempty"`
+	ReadConcernMajorityOpTime *OpTime `bson:"readConcernMajorityOpTime,omitempty"`
+	AppliedOpTime             *OpTime `bson:"appliedOpTime,omitempty"`
+	DurableOpTime             *OpTime `bson:"durableOpTime,omitempty"`
+}
+
+// setMetrics sets the optime metrics of the replica set
+func (optimes *Optimes) setMetrics(set string) {
+	for optimeType, optime := range map[string]*OpTime{
+		"last_committed":        optimes.LastCommittedOpTime,
+		"read_concern_majority": optimes.ReadConcernMajorityOpTime,
+		"applied":               optimes.AppliedOpTime,
+		"durable":               optimes.DurableOpTime,
+	} {
+		if optime == nil {
+			continue
+		}
+		optimeTimestamp.WithLabelValues(set, optimeType).Set(optime.Unix())
+		if optime.Term != nil {
+			optimeTerm.WithLabelValues(set, optimeType).Set(float64(*optime.Term))
+		}
+	}
 }
 
 // Member represents an array element of ReplSetStatus.Members
 type Member struct {
-	Name                 string      `bson:"name"`
-	Self                 *bool       `bson:"self,omitempty"`
-	Health               *int32      `bson:"health,omitempty"`
-	State                int32       `bson:"state"`
-	StateStr             string      `bson:"stateStr"`
-	Uptime               float64     `bson:"uptime"`
-	Optime               interface{} `bson:"optime"`
-	OptimeDate           time.Time   `bson:"optimeDate"`
-	ElectionTime         *time.Time  `bson:"electionTime,omitempty"`
-	ElectionDate         *time.Time  `bson:"electionDate,omitempty"`
-	LastHeartbeat        *time.Time  `bson:"lastHeartbeat,omitempty"`
-	LastHeartbeatRecv    *time.Time  `bson:"lastHeartbeatRecv,omitempty"`
-	LastHeartbeatMessage *string     `bson:"lastHeartbeatMessage,omitempty"`
-	PingMs               *float64    `bson:"pingMs,omitempty"`
-	SyncingTo            *string     `bson:"syncingTo,omitempty"`
-	ConfigVersion        *int32      `bson:"configVersion,omitempty"`
+	Name                 string     `bson:"name"`
+	Self                 *bool      `bson:"self,omitempty"`
+	Health               *int32     `bson:"health,omitempty"`
+	State                int32      `bson:"state"`
+	StateStr             string     `bson:"stateStr"`
+	Uptime               float64    `bson:"uptime"`
+	Optime               *OpTime    `bson:"optime,omitempty"`
+	OptimeDate           time.Time  `bson:"optimeDate"`
+	OptimeDurable        *OpTime    `bson:"optimeDurable,omitempty"`
+	OptimeDurableDate    *time.Time `bson:"optimeDurableDate,omitempty"`
+	ElectionTime         *time.Time `bson:"electionTime,omitempty"`
+	ElectionDate         *time.Time `bson:"electionDate,omitempty"`
+	LastHeartbeat        *time.Time `bson:"lastHeartbeat,omitempty"`
+	LastHeartbeatRecv    *time.Time `bson:"lastHeartbeatRecv,omitempty"`
+	LastHeartbeatMessage *string    `bson:"lastHeartbeatMessage,omitempty"`
+	PingMs               *float64   `bson:"pingMs,omitempty"`
+	SyncingTo            *string    `bson:"syncingTo,omitempty"`
+	SyncSourceHost       *string    `bson:"syncSourceHost,omitempty"`
+	ConfigVersion        *int32     `bson:"configVersion,omitempty"`
+}
+
+// memberOptimes returns the state and optime date of every member
+func (replStatus *ReplSetStatus) memberOptimes() []shared.ReplSetMemberOptime {
+	optimes := make([]shared.ReplSetMemberOptime, 0, len(replStatus.Members))
+	for _, member := range replStatus.Members {
+		optimes = append(optimes, shared.ReplSetMemberOptime{Name: member.Name, State: member.State, OptimeDate: member.OptimeDate})
+	}
+	return optimes
+}
+
+// referenceOptimeDate returns the optime date the replication lag is computed
+// against: the primary's, or the most recent one when there is no primary.
+func (replStatus *ReplSetStatus) referenceOptimeDate() (time.Time, bool) {
+	return shared.ReplSetReferenceOptimeDate(replStatus.memberOptimes())
+}
+
+// selfUptime returns the uptime of the member the exporter is connected to
+func (replStatus *ReplSetStatus) selfUptime() float64 {
+	for _, member := range replStatus.Members {
+		if member.Self != nil && *member.Self {
+			return member.Uptime
+		}
+	}
+	return 0
+}
+
+// syncSource returns the member this member replicates from, syncingTo was
+// replaced by syncSourceHost in version 4.4
+func (member *Member) syncSource() string {
+	if member.SyncSourceHost != nil && *member.SyncSourceHost != "" {
+		return *member.SyncSourceHost
+	}
+	if member.SyncingTo != nil {
+		return *member.SyncingTo
+	}
+	return ""
+}
+
+// chainingDepths walks the sync source graph from every member to the primary
+// and returns the number of hops per member. Members whose chain does not end
+// at the primary (no sync source, unknown member or a cycle) are left out.
+func (replStatus *ReplSetStatus) chainingDepths() map[string]int {
+	sources := make(map[string]string)
+	primary := ""
+	for _, member := range replStatus.Members {
+		if member.State == 1 {
+			primary = member.Name
+		}
+		sources[member.Name] = member.syncSource()
+	}
+
+	depths := make(map[string]int)
+	if primary == "" {
+		return depths
+	}
+	for name := range sources {
+		depth := 0
+		for current := name; current != primary; depth++ {
+			if depth >= len(sources) {
+				depth = -1
+				break
+			}
+			next, ok := sources[current]
+			if !ok || next == "" {
+				depth = -1
+				break
+			}
+			current = next
+		}
+		if depth >= 0 {
+			depths[name] = depth
+		}
+	}
+	return depths
 }
 
 // Export exports the replSetGetStatus stati to be consumed by prometheus
@@ -156,6 +564,24 @@ func (replStatus *ReplSetStatus) Export(ch chan<- prometheus.Metric) {
 	memberLastHeartbeatRecv.Reset()
 	memberPingMs.Reset()
 	memberConfigVersion.Reset()
+	memberOptimeTerm.Reset()
+	memberOptimeDurableDate.Reset()
+	memberOptimeDurableTerm.Reset()
+	optimeTimestamp.Reset()
+	optimeTerm.Reset()
+	memberSyncSource.Reset()
+	memberChainingDepth.Reset()
+	memberHeartbeatError.Reset()
+	initialSyncAttempts.Reset()
+	initialSyncFetchedMissingDocs.Reset()
+	initialSyncAppliedOps.Reset()
+	initialSyncElapsedSeconds.Reset()
+	initialSyncDatabases.Reset()
+	initialSyncCollectionDocuments.Reset()
+	initialSyncCollectionElapsedSeconds.Reset()
+	rollbackId.Reset()
+	memberReplicationLag.Reset()
+	memberMajorityCommitLag.Reset()
 
 	myState.WithLabelValues(replStatus.Set).Set(float64(replStatus.MyState))
 	date.WithLabelValues(replStatus.Set).Set(float64(replStatus.Date.Unix()))
@@ -171,6 +597,48 @@ func (replStatus *ReplSetStatus) Export(ch chan<- prometheus.Metric) {
 		heartbeatIntervalMillis.WithLabelValues(replStatus.Set).Set(*replStatus.HeartbeatIntervalMillis)
 	}
 
+	// new in version 3.4
+	if replStatus.Optimes != nil {
+		replStatus.Optimes.setMetrics(replStatus.Set)
+	}
+
+	for _, member := range replStatus.Members {
+		if member.State != 1 {
+			continue
+		}
+		current := primaryObservation{Name: member.Name}
+		// new in version 3.2
+		if replStatus.Term != nil {
+			current.Term = *replStatus.Term
+		}
+		if observePrimary(replStatus.Set, current) {
+			primaryChangesTotal.WithLabelValues(replStatus.Set).Inc()
+			lastPrimaryChangeTimestamp.WithLabelValues(replStatus.Set).Set(float64(replStatus.Date.Unix()))
+		}
+		// initialise the counter so that it is exported before the first change
+		primaryChangesTotal.WithLabelValues(replStatus.Set).Add(0)
+	}
+
+	// new in version 3.4, only returned during an initial sync
+	if replStatus.InitialSyncStatus != nil {
+		replStatus.InitialSyncStatus.setMetrics(replStatus.Set, replStatus.Date)
+	}
+
+	if replStatus.RBID != nil {
+		rollbackId.WithLabelValues(replStatus.Set).Set(float64(*replStatus.RBID))
+		if observeRBID(replStatus.Set, rbidObservation{RBID: *replStatus.RBID, Uptime: replStatus.selfUptime(), Date: replStatus.Date}) {
+			rollbacksTotal.WithLabelValues(replStatus.Set).Inc()
+		}
+		// initialise the counter so that it is exported before the first rollback
+		rollbacksTotal.WithLabelValues(replStatus.Set).Add(0)
+	}
+
+	for name, depth := range replStatus.chainingDepths() {
+		memberChainingDepth.WithLabelValues(replStatus.Set, name).Set(float64(depth))
+	}
+
+	replicationLags := shared.ReplSetReplicationLags(replStatus.memberOptimes())
+
 	for _, member := range replStatus.Members {
 		if member.Self != nil {
 			labels := prometheus.Labels{
@@ -180,12 +648,13 @@ func (replStatus *ReplSetStatus) Export(ch chan<- prometheus.Metric) {
 			myName.With(labels).Set(1)
 		}
 		ls := prometheus.Labels{
-			"set":   replStatus.Set,
-			"name":  member.Name,
-			"state": member.StateStr,
+			"set":  replStatus.Set,
+			"name": member.Name,
 		}
 
-		memberState.With(ls).Set(float64(member.State))
+		for state, stateStr := range shared.ReplSetMemberStates {
+			memberState.WithLabelValues(replStatus.Set, member.Name, stateStr).Set(boolValue(state == member.State))
+		}
 
 		// ReplSetStatus.Member.Health is not available on the node you're connected to
 		if member.Health != nil {
@@ -195,6 +664,15 @@ func (replStatus *ReplSetStatus) Export(ch chan<- prometheus.Metric) {
 		memberUptime.With(ls).Set(member.Uptime)
 
 		memberOptimeDate.With(ls).Set(float64(member.OptimeDate.Unix()))
+		if member.Optime != nil && member.Optime.Term != nil {
+			memberOptimeTerm.With(ls).Set(float64(*member.Optime.Term))
+		}
+		if member.OptimeDurableDate != nil {
+			memberOptimeDurableDate.With(ls).Set(float64((*member.OptimeDurableDate).Unix()))
+		}
+		if member.OptimeDurable != nil && member.OptimeDurable.Term != nil {
+			memberOptimeDurableTerm.With(ls).Set(float64(*member.OptimeDurable.Term))
+		}
 
 		// ReplSetGetStatus.Member.ElectionTime is only available on the PRIMARY
 		if member.ElectionDate != nil {
@@ -212,6 +690,26 @@ func (replStatus *ReplSetStatus) Export(ch chan<- prometheus.Metric) {
 		if member.ConfigVersion != nil {
 			memberConfigVersion.With(ls).Set(float64(*member.ConfigVersion))
 		}
+
+		if source := member.syncSource(); source != "" {
+			memberSyncSource.WithLabelValues(replStatus.Set, member.Name, source).Set(1)
+		}
+		// heartbeats are not sent to the member you're connected to
+		if member.Self == nil {
+			heartbeatError := member.LastHeartbeatMessage != nil && *member.LastHeartbeatMessage != ""
+			memberHeartbeatError.WithLabelValues(replStatus.Set, member.Name).Set(boolValue(heartbeatError))
+		}
+
+		// arbiters and unreachable members do not report an optime
+		if !member.OptimeDate.IsZero() {
+			if lag, ok := replicationLags[member.Name]; ok {
+				memberReplicationLag.With(ls).Set(lag)
+			}
+			if replStatus.Optimes != nil && replStatus.Optimes.LastCommittedOpTime != nil {
+				lastCommitted := replStatus.Optimes.LastCommittedOpTime.Unix()
+				memberMajorityCommitLag.With(ls).Set(math.Max(0, float64(member.OptimeDate.Unix())-lastCommitted))
+			}
+		}
 	}
 	// collect metrics
 	myName.Collect(ch)
@@ -229,6 +727,27 @@ func (replStatus *ReplSetStatus) Export(ch chan<- prometheus.Metric) {
 	memberLastHeartbeatRecv.Collect(ch)
 	memberPingMs.Collect(ch)
 	memberConfigVersion.Collect(ch)
+	memberOptimeTerm.Collect(ch)
+	memberOptimeDurableDate.Collect(ch)
+	memberOptimeDurableTerm.Collect(ch)
+	optimeTimestamp.Collect(ch)
+	optimeTerm.Collect(ch)
+	primaryChangesTotal.Collect(ch)
+	lastPrimaryChangeTimestamp.Collect(ch)
+	memberSyncSource.Collect(ch)
+	memberChainingDepth.Collect(ch)
+	memberHeartbeatError.Collect(ch)
+	initialSyncAttempts.Collect(ch)
+	initialSyncFetchedMissingDocs.Collect(ch)
+	initialSyncAppliedOps.Collect(ch)
+	initialSyncElapsedSeconds.Collect(ch)
+	initialSyncDatabases.Collect(ch)
+	initialSyncCollectionDocuments.Collect(ch)
+	initialSyncCollectionElapsedSeconds.Collect(ch)
+	rollbackId.Collect(ch)
+	rollbacksTotal.Collect(ch)
+	memberReplicationLag.Collect(ch)
+	memberMajorityCommitLag.Collect(ch)
 }
 
 // Describe describes the replSetGetStatus metrics for prometheus
@@ -247,6 +766,27 @@ func (replStatus *ReplSetStatus) Describe(ch chan<- *prometheus.Desc) {
 	memberLastHeartbeatRecv.Describe(ch)
 	memberPingMs.Describe(ch)
 	memberConfigVersion.Describe(ch)
+	memberOptimeTerm.Describe(ch)
+	memberOptimeDurableDate.Describe(ch)
+	memberOptimeDurableTerm.Describe(ch)
+	optimeTimestamp.Describe(ch)
+	optimeTerm.Describe(ch)
+	primaryChangesTotal.Describe(ch)
+	lastPrimaryChangeTimestamp.Describe(ch)
+	memberSyncSource.Describe(ch)
+	memberChainingDepth.Describe(ch)
+	memberHeartbeatError.Describe(ch)
+	initialSyncAttempts.Describe(ch)
+	initialSyncFetchedMissingDocs.Describe(ch)
+	initialSyncAppliedOps.Describe(ch)
+	initialSyncElapsedSeconds.Describe(ch)
+	initialSyncDatabases.Describe(ch)
+	initialSyncCollectionDocuments.Describe(ch)
+	initialSyncCollectionElapsedSeconds.Describe(ch)
+	rollbackId.Describe(ch)
+	rollbacksTotal.Describe(ch)
+	memberReplicationLag.Describe(ch)
+	memberMajorityCommitLag.Describe(ch)
 }
 
 // GetReplSetStatus returns the replica status info
@@ -257,5 +797,26 @@ func GetReplSetStatus(session *mgo.Session) *ReplSetStatus {
 		glog.Error("Failed to get replSet status.")
 		return nil
 	}
+
+	// the initial sync progress is only reported on request, while in STARTUP2
+	if result.MyState == 5 {
+		withInitialSync := &ReplSetStatus{}
+		err = session.DB("admin").Run(bson.D{{"replSetGetStatus", 1}, {"initialSync", 1}}, withInitialSync)
+		if err != nil {
+			glog.Errorf("Failed to get replSet initial sync status: %s", err)
+		} else {
+			result = withInitialSync
+		}
+	}
+
+	rbid := struct {
+		RBID int32 `bson:"rbid"`
+	}{}
+	err = session.DB("admin").Run(bson.D{{"replSetGetRBID", 1}}, &rbid)
+	if err != nil {
+		glog.Errorf("Failed to get replSet rollback id: %s", err)
+	} else {
+		result.RBID = &rbid.RBID
+	}
 	return result
 }
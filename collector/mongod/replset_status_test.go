@@ -0,0 +1,158 @@
+package collector_mongod
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func Test_ReplSetReferenceOptimeDate(t *testing.T) {
+	now := time.Now()
+	status := &ReplSetStatus{
+		Members: []Member{
+			{Name: "a:27017", State: 2, OptimeDate: now.Add(-5 * time.Second)},
+			{Name: "b:27017", State: 1, OptimeDate: now.Add(-10 * time.Second)},
+			{Name: "c:27017", State: 2, OptimeDate: now},
+		},
+	}
+	if ref, ok := status.referenceOptimeDate(); !ok || !ref.Equal(now.Add(-10*time.Second)) {
+		t.Error("the primary's optime was not used as reference")
+	}
+
+	status.Members[1].State = 2
+	if ref, ok := status.referenceOptimeDate(); !ok || !ref.Equal(now) {
+		t.Error("the most recent optime was not used as reference without a primary")
+	}
+
+	if _, ok := (&ReplSetStatus{}).referenceOptimeDate(); ok {
+		t.Error("an empty replica set should not have a reference optime")
+	}
+}
+
+func Test_ParseOpTime(t *testing.T) {
+	pv0, _ := bson.Marshal(bson.M{"optime": bson.MongoTimestamp(1500000000 << 32)})
+	pv1, _ := bson.Marshal(bson.M{"optime": bson.M{"ts": bson.MongoTimestamp(1500000000 << 32), "t": int64(3)}})
+
+	member := &Member{}
+	if err := bson.Unmarshal(pv0, member); err != nil {
+		t.Fatal(err)
+	}
+	if member.Optime.Unix() != 1500000000 || member.Optime.Term != nil {
+		t.Error("protocol version 0 optime was not parsed")
+	}
+
+	member = &Member{}
+	if err := bson.Unmarshal(pv1, member); err != nil {
+		t.Fatal(err)
+	}
+	if member.Optime.Unix() != 1500000000 || member.Optime.Term == nil || *member.Optime.Term != 3 {
+		t.Error("protocol version 1 optime was not parsed")
+	}
+}
+
+func Test_ObservePrimary(t *testing.T) {
+	if observePrimary("rs-observe", primaryObservation{Name: "a:27017", Term: 1}) {
+		t.Error("the first observation should not be a primary change")
+	}
+	if observePrimary("rs-observe", primaryObservation{Name: "a:27017", Term: 1}) {
+		t.Error("the same primary and term should not be a primary change")
+	}
+	if !observePrimary("rs-observe", primaryObservation{Name: "b:27017", Term: 2}) {
+		t.Error("a new primary was not reported as a change")
+	}
+	if !observePrimary("rs-observe", primaryObservation{Name: "b:27017", Term: 3}) {
+		t.Error("a re-election in a new term was not reported as a change")
+	}
+}
+
+func Test_ReplSetChainingDepths(t *testing.T) {
+	primary, secondary, chained := "a:27017", "b:27017", "c:27017"
+	status := &ReplSetStatus{
+		Members: []Member{
+			{Name: primary, State: 1},
+			{Name: secondary, State: 2, SyncingTo: &primary},
+			{Name: chained, State: 2, SyncSourceHost: &secondary},
+			{Name: "d:27017", State: 7},
+		},
+	}
+	depths := status.chainingDepths()
+	if depths[primary] != 0 || depths[secondary] != 1 || depths[chained] != 2 {
+		t.Errorf("unexpected chaining depths: %v", depths)
+	}
+	if _, ok := depths["d:27017"]; ok {
+		t.Error("a member without a sync source should not have a chaining depth")
+	}
+
+	// a sync source cycle without a primary in the chain
+	status.Members[1].SyncingTo = &chained
+	if _, ok := status.chainingDepths()[secondary]; ok {
+		t.Error("a member in a sync source cycle should not have a chaining depth")
+	}
+}
+
+func Test_InitialSyncClonedDatabases(t *testing.T) {
+	data, _ := bson.Marshal(bson.M{
+		"initialSyncStatus": bson.M{
+			"failedInitialSyncAttempts": 1,
+			"databases": bson.M{
+				"databasesCloned": 1,
+				"admin": bson.M{
+					"collections":       1,
+					"clonedCollections": 1,
+					"admin.system.version": bson.M{
+						"documentsToCopy": 2,
+						"documentsCopied": 2,
+						"elapsedMillis":   5,
+					},
+				},
+				"test": bson.M{
+					"collections": 1,
+					"test.users": bson.M{
+						"documentsToCopy": 100,
+						"documentsCopied": 40,
+					},
+				},
+			},
+		},
+	})
+	status := &ReplSetStatus{}
+	if err := bson.Unmarshal(data, status); err != nil {
+		t.Fatal(err)
+	}
+
+	cloned, total, collections := status.InitialSyncStatus.clonedDatabases()
+	if cloned != 1 || total != 2 {
+		t.Errorf("unexpected database counts: cloned %v, total %v", cloned, total)
+	}
+	if len(collections) != 2 || collections["test.users"] == nil || collections["test.users"].DocumentsCopied != 40 {
+		t.Errorf("unexpected collections: %v", collections)
+	}
+}
+
+func Test_ObserveRBID(t *testing.T) {
+	start := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	observe := func(rbid int32, uptime float64, after time.Duration) bool {
+		return observeRBID("rs-rbid", rbidObservation{RBID: rbid, Uptime: uptime, Date: start.Add(after)})
+	}
+	if observe(1, 100, 0) {
+		t.Error("the first observation should not be a rollback")
+	}
+	if observe(1, 110, 10*time.Second) {
+		t.Error("an unchanged rbid should not be a rollback")
+	}
+	if !observe(2, 120, 20*time.Second) {
+		t.Error("a changed rbid was not reported as a rollback")
+	}
+	if observe(7, 5, 60*time.Second) {
+		t.Error("an rbid changed by a restart should not be a rollback")
+	}
+	if !observe(8, 15, 70*time.Second) {
+		t.Error("a rollback after a restart was not reported")
+	}
+
+	// the uptime grew past the previous sample, but less than the time in between
+	if observe(8, 30, 85*time.Second) || observe(9, 45, 145*time.Second) {
+		t.Error("an rbid changed by a restart with a longer uptime should not be a rollback")
+	}
+}
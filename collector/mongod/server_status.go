@@ -65,6 +65,15 @@ type ServerStatus struct {
 	InMemory      *WiredTigerStats    `bson:"inMemory"`
 	RocksDb       *RocksDbStats       `bson:"rocksdb"`
 	WiredTiger    *WiredTigerStats    `bson:"wiredTiger"`
+
+	TCMalloc *TCMallocStats `bson:"tcmalloc"`
+
+	LogicalSessionRecordCache *LogicalSessionStats `bson:"logicalSessionRecordCache"`
+	Transactions              *TransactionStats    `bson:"transactions"`
+
+	ElectionMetrics *ElectionMetrics `bson:"electionMetrics"`
+
+	ShardingStatistics *ShardingStatistics `bson:"shardingStatistics"`
 }
 
 // Export exports the server status to be consumed by prometheus.
@@ -127,6 +136,21 @@ func (status *ServerStatus) Export(ch chan<- prometheus.Metric) {
 	if status.WiredTiger != nil {
 		status.WiredTiger.Export(ch)
 	}
+	if status.TCMalloc != nil {
+		status.TCMalloc.Export(ch)
+	}
+	if status.LogicalSessionRecordCache != nil {
+		status.LogicalSessionRecordCache.Export(ch)
+	}
+	if status.Transactions != nil {
+		status.Transactions.Export(ch)
+	}
+	if status.ElectionMetrics != nil {
+		status.ElectionMetrics.Export(ch)
+	}
+	if status.ShardingStatistics != nil {
+		status.ShardingStatistics.Export(ch)
+	}
 
 	// If db.serverStatus().storageEngine does not exist (3.0+ only) and status.BackgroundFlushing does (MMAPv1 only), default to mmapv1
 	// https://docs.mongodb.com/v3.0/reference/command/serverStatus/#storageengine
@@ -200,16 +224,41 @@ func (status *ServerStatus) Describe(ch chan<- *prometheus.Desc) {
 	if status.WiredTiger != nil {
 		status.WiredTiger.Describe(ch)
 	}
+	if status.TCMalloc != nil {
+		status.TCMalloc.Describe(ch)
+	}
+	if status.LogicalSessionRecordCache != nil {
+		status.LogicalSessionRecordCache.Describe(ch)
+	}
+	if status.Transactions != nil {
+		status.Transactions.Describe(ch)
+	}
+	if status.ElectionMetrics != nil {
+		status.ElectionMetrics.Describe(ch)
+	}
+	if status.ShardingStatistics != nil {
+		status.ShardingStatistics.Describe(ch)
+	}
 }
 
-// GetServerStatus returns the server status info.
-func GetServerStatus(session *mgo.Session) *ServerStatus {
+// GetServerStatus returns the server status info. A tcmallocVerbosity greater
+// than zero requests the tcmalloc section, 2 adds the per-size-class breakdown.
+func GetServerStatus(session *mgo.Session, tcmallocVerbosity int) *ServerStatus {
 	result := &ServerStatus{}
-	err := session.DB("admin").Run(bson.D{{"serverStatus", 1}, {"recordStats", 0}}, result)
+	cmd := bson.D{{"serverStatus", 1}, {"recordStats", 0}}
+	if tcmallocVerbosity > 0 {
+		cmd = append(cmd, bson.DocElem{"tcmalloc", tcmallocVerbosity})
+	}
+	err := session.DB("admin").Run(cmd, result)
 	if err != nil {
 		glog.Error("Failed to get server status.")
 		return nil
 	}
 
+	// older servers return the tcmalloc section by default, only export it when requested
+	if tcmallocVerbosity <= 0 {
+		result.TCMalloc = nil
+	}
+
 	return result
 }
@@ -0,0 +1,237 @@
+package collector_mongod
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	shardingStaleConfigErrorsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding",
+		Name:      "stale_config_errors_total",
+		Help:      "The total number of times a thread hit a stale config exception",
+	})
+	shardingDonorMoveChunksStartedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding",
+		Name:      "donor_move_chunks_started_total",
+		Help:      "The total number of chunk migrations this member started as the donor shard primary",
+	})
+	shardingDonorMoveChunksTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding",
+		Name:      "donor_move_chunks_total",
+		Help:      "The total number of chunk migrations this member committed or aborted as the donor shard primary",
+	}, []string{"result"})
+	shardingDonorMoveChunkAbortsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding",
+		Name:      "donor_move_chunk_aborts_total",
+		Help:      "The total number of chunk migrations this member aborted as the donor shard primary because of a lock timeout or a conflicting index operation, a subset of the aborted migrations",
+	}, []string{"cause"})
+	shardingRecipientMoveChunksStartedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding",
+		Name:      "recipient_move_chunks_started_total",
+		Help:      "The total number of chunk migrations this member started to receive as the recipient shard primary",
+	})
+	shardingDocsClonedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding",
+		Name:      "docs_cloned_total",
+		Help:      "The total number of documents cloned during chunk migrations as the donor and as the recipient shard primary",
+	}, []string{"side"})
+	shardingBytesClonedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding",
+		Name:      "bytes_cloned_total",
+		Help:      "The total number of bytes cloned during chunk migrations as the donor and as the recipient shard primary",
+	}, []string{"side"})
+	shardingDocsDeletedOnDonorTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding",
+		Name:      "docs_deleted_on_donor_total",
+		Help:      "The total number of documents deleted by the range deleter after chunk migrations as the donor shard primary",
+	})
+	shardingDonorChunkCloneSecondsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding",
+		Name:      "donor_chunk_clone_seconds_total",
+		Help:      "The total time spent in the clone phase of chunk migrations as the donor shard primary",
+	})
+	shardingCriticalSectionSecondsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding",
+		Name:      "critical_section_seconds_total",
+		Help:      "The total time spent in the critical section of chunk migrations as the donor and as the recipient shard primary",
+	}, []string{"side"})
+	shardingDonorCriticalSectionCommitSecondsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding",
+		Name:      "donor_critical_section_commit_seconds_total",
+		Help:      "The total time spent in the commit phase of the critical section of chunk migrations as the donor shard primary, a part of the donor critical section time",
+	})
+	shardingRangeDeleterTasks = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding",
+		Name:      "range_deleter_tasks",
+		Help:      "The number of queued and running range deletion tasks of migrated chunks",
+	})
+	shardingUnfinishedMigrationFromPreviousPrimary = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding",
+		Name:      "unfinished_migration_from_previous_primary",
+		Help:      "The number of unfinished chunk migrations left by the previous primary after a stepdown, recovered by this member",
+	})
+	shardingCatalogCacheEntries = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding_catalog_cache",
+		Name:      "entries",
+		Help:      "The number of database and collection entries in the routing table cache",
+	}, []string{"type"})
+	shardingCatalogCacheStaleConfigErrorsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding_catalog_cache",
+		Name:      "stale_config_errors_total",
+		Help:      "The total number of stale config exceptions that caused a routing table cache refresh",
+	})
+	shardingCatalogCacheRefreshWaitSecondsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding_catalog_cache",
+		Name:      "refresh_wait_seconds_total",
+		Help:      "The total time threads waited for routing table cache refreshes",
+	})
+	shardingCatalogCacheRefreshesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding_catalog_cache",
+		Name:      "refreshes_total",
+		Help:      "The total number of incremental and full routing table cache refreshes started",
+	}, []string{"type"})
+	shardingCatalogCacheRefreshFailuresTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding_catalog_cache",
+		Name:      "refresh_failures_total",
+		Help:      "The total number of routing table cache refreshes that failed",
+	})
+	shardingCatalogCacheActiveRefreshes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding_catalog_cache",
+		Name:      "active_refreshes",
+		Help:      "The number of incremental and full routing table cache refreshes waiting to complete",
+	}, []string{"type"})
+)
+
+// CatalogCacheStats keeps the data of the shardingStatistics catalogCache section (4.0+)
+type CatalogCacheStats struct {
+	NumDatabaseEntries               float64 `bson:"numDatabaseEntries"`
+	NumCollectionEntries             float64 `bson:"numCollectionEntries"`
+	CountStaleConfigErrors           float64 `bson:"countStaleConfigErrors"`
+	TotalRefreshWaitTimeMicros       float64 `bson:"totalRefreshWaitTimeMicros"`
+	NumActiveIncrementalRefreshes    float64 `bson:"numActiveIncrementalRefreshes"`
+	CountIncrementalRefreshesStarted float64 `bson:"countIncrementalRefreshesStarted"`
+	NumActiveFullRefreshes           float64 `bson:"numActiveFullRefreshes"`
+	CountFullRefreshesStarted        float64 `bson:"countFullRefreshesStarted"`
+	CountFailedRefreshes             float64 `bson:"countFailedRefreshes"`
+}
+
+// ShardingStatistics keeps the data of the serverStatus shardingStatistics section (3.4+)
+type ShardingStatistics struct {
+	CountStaleConfigErrors                            float64            `bson:"countStaleConfigErrors"`
+	CountDonorMoveChunkStarted                        float64            `bson:"countDonorMoveChunkStarted"`
+	CountDonorMoveChunkCommitted                      float64            `bson:"countDonorMoveChunkCommitted"`
+	CountDonorMoveChunkAborted                        float64            `bson:"countDonorMoveChunkAborted"`
+	CountDonorMoveChunkLockTimeout                    float64            `bson:"countDonorMoveChunkLockTimeout"`
+	CountDonorMoveChunkAbortConflictingIndexOperation float64            `bson:"countDonorMoveChunkAbortConflictingIndexOperation"`
+	CountRecipientMoveChunkStarted                    float64            `bson:"countRecipientMoveChunkStarted"`
+	TotalDonorChunkCloneTimeMillis                    float64            `bson:"totalDonorChunkCloneTimeMillis"`
+	TotalCriticalSectionCommitTimeMillis              float64            `bson:"totalCriticalSectionCommitTimeMillis"`
+	TotalCriticalSectionTimeMillis                    float64            `bson:"totalCriticalSectionTimeMillis"`
+	TotalRecipientCriticalSectionTimeMillis           float64            `bson:"totalRecipientCriticalSectionTimeMillis"`
+	CountDocsClonedOnRecipient                        float64            `bson:"countDocsClonedOnRecipient"`
+	CountDocsClonedOnDonor                            float64            `bson:"countDocsClonedOnDonor"`
+	CountBytesClonedOnRecipient                       float64            `bson:"countBytesClonedOnRecipient"`
+	CountBytesClonedOnDonor                           float64            `bson:"countBytesClonedOnDonor"`
+	CountDocsDeletedOnDonor                           float64            `bson:"countDocsDeletedOnDonor"`
+	RangeDeleterTasks                                 float64            `bson:"rangeDeleterTasks"`
+	UnfinishedMigrationFromPreviousPrimary            float64            `bson:"unfinishedMigrationFromPreviousPrimary"`
+	CatalogCache                                      *CatalogCacheStats `bson:"catalogCache"`
+}
+
+// Export exports the data to prometheus.
+func (stats *ShardingStatistics) Export(ch chan<- prometheus.Metric) {
+	shardingStaleConfigErrorsTotal.Set(stats.CountStaleConfigErrors)
+	shardingDonorMoveChunksStartedTotal.Set(stats.CountDonorMoveChunkStarted)
+	shardingDonorMoveChunksTotal.WithLabelValues("committed").Set(stats.CountDonorMoveChunkCommitted)
+	shardingDonorMoveChunksTotal.WithLabelValues("aborted").Set(stats.CountDonorMoveChunkAborted)
+	shardingDonorMoveChunkAbortsTotal.WithLabelValues("lock_timeout").Set(stats.CountDonorMoveChunkLockTimeout)
+	shardingDonorMoveChunkAbortsTotal.WithLabelValues("conflicting_index_operation").Set(stats.CountDonorMoveChunkAbortConflictingIndexOperation)
+	shardingRecipientMoveChunksStartedTotal.Set(stats.CountRecipientMoveChunkStarted)
+	shardingDocsClonedTotal.WithLabelValues("donor").Set(stats.CountDocsClonedOnDonor)
+	shardingDocsClonedTotal.WithLabelValues("recipient").Set(stats.CountDocsClonedOnRecipient)
+	shardingBytesClonedTotal.WithLabelValues("donor").Set(stats.CountBytesClonedOnDonor)
+	shardingBytesClonedTotal.WithLabelValues("recipient").Set(stats.CountBytesClonedOnRecipient)
+	shardingDocsDeletedOnDonorTotal.Set(stats.CountDocsDeletedOnDonor)
+	shardingDonorChunkCloneSecondsTotal.Set(stats.TotalDonorChunkCloneTimeMillis / 1000)
+	shardingCriticalSectionSecondsTotal.WithLabelValues("donor").Set(stats.TotalCriticalSectionTimeMillis / 1000)
+	shardingCriticalSectionSecondsTotal.WithLabelValues("recipient").Set(stats.TotalRecipientCriticalSectionTimeMillis / 1000)
+	shardingDonorCriticalSectionCommitSecondsTotal.Set(stats.TotalCriticalSectionCommitTimeMillis / 1000)
+	shardingRangeDeleterTasks.Set(stats.RangeDeleterTasks)
+	shardingUnfinishedMigrationFromPreviousPrimary.Set(stats.UnfinishedMigrationFromPreviousPrimary)
+
+	shardingStaleConfigErrorsTotal.Collect(ch)
+	shardingDonorMoveChunksStartedTotal.Collect(ch)
+	shardingDonorMoveChunksTotal.Collect(ch)
+	shardingDonorMoveChunkAbortsTotal.Collect(ch)
+	shardingRecipientMoveChunksStartedTotal.Collect(ch)
+	shardingDocsClonedTotal.Collect(ch)
+	shardingBytesClonedTotal.Collect(ch)
+	shardingDocsDeletedOnDonorTotal.Collect(ch)
+	shardingDonorChunkCloneSecondsTotal.Collect(ch)
+	shardingCriticalSectionSecondsTotal.Collect(ch)
+	shardingDonorCriticalSectionCommitSecondsTotal.Collect(ch)
+	shardingRangeDeleterTasks.Collect(ch)
+	shardingUnfinishedMigrationFromPreviousPrimary.Collect(ch)
+
+	if stats.CatalogCache != nil {
+		cache := stats.CatalogCache
+		shardingCatalogCacheEntries.WithLabelValues("database").Set(cache.NumDatabaseEntries)
+		shardingCatalogCacheEntries.WithLabelValues("collection").Set(cache.NumCollectionEntries)
+		shardingCatalogCacheStaleConfigErrorsTotal.Set(cache.CountStaleConfigErrors)
+		shardingCatalogCacheRefreshWaitSecondsTotal.Set(cache.TotalRefreshWaitTimeMicros / 1000000)
+		shardingCatalogCacheRefreshesTotal.WithLabelValues("incremental").Set(cache.CountIncrementalRefreshesStarted)
+		shardingCatalogCacheRefreshesTotal.WithLabelValues("full").Set(cache.CountFullRefreshesStarted)
+		shardingCatalogCacheRefreshFailuresTotal.Set(cache.CountFailedRefreshes)
+		shardingCatalogCacheActiveRefreshes.WithLabelValues("incremental").Set(cache.NumActiveIncrementalRefreshes)
+		shardingCatalogCacheActiveRefreshes.WithLabelValues("full").Set(cache.NumActiveFullRefreshes)
+
+		shardingCatalogCacheEntries.Collect(ch)
+		shardingCatalogCacheStaleConfigErrorsTotal.Collect(ch)
+		shardingCatalogCacheRefreshWaitSecondsTotal.Collect(ch)
+		shardingCatalogCacheRefreshesTotal.Collect(ch)
+		shardingCatalogCacheRefreshFailuresTotal.Collect(ch)
+		shardingCatalogCacheActiveRefreshes.Collect(ch)
+	}
+}
+
+// Describe describes the metrics for prometheus
+func (stats *ShardingStatistics) Describe(ch chan<- *prometheus.Desc) {
+	shardingStaleConfigErrorsTotal.Describe(ch)
+	shardingDonorMoveChunksStartedTotal.Describe(ch)
+	shardingDonorMoveChunksTotal.Describe(ch)
+	shardingDonorMoveChunkAbortsTotal.Describe(ch)
+	shardingRecipientMoveChunksStartedTotal.Describe(ch)
+	shardingDocsClonedTotal.Describe(ch)
+	shardingBytesClonedTotal.Describe(ch)
+	shardingDocsDeletedOnDonorTotal.Describe(ch)
+	shardingDonorChunkCloneSecondsTotal.Describe(ch)
+	shardingCriticalSectionSecondsTotal.Describe(ch)
+	shardingDonorCriticalSectionCommitSecondsTotal.Describe(ch)
+	shardingRangeDeleterTasks.Describe(ch)
+	shardingUnfinishedMigrationFromPreviousPrimary.Describe(ch)
+	shardingCatalogCacheEntries.Describe(ch)
+	shardingCatalogCacheStaleConfigErrorsTotal.Describe(ch)
+	shardingCatalogCacheRefreshWaitSecondsTotal.Describe(ch)
+	shardingCatalogCacheRefreshesTotal.Describe(ch)
+	shardingCatalogCacheRefreshFailuresTotal.Describe(ch)
+	shardingCatalogCacheActiveRefreshes.Describe(ch)
+}
@@ -0,0 +1,61 @@
+package collector_mongod
+
+import (
+	"testing"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func Test_ParserShardingStatistics(t *testing.T) {
+	data, err := bson.Marshal(bson.M{
+		"host": "shard0:27018",
+		"shardingStatistics": bson.M{
+			"countStaleConfigErrors":                            int64(3),
+			"countDonorMoveChunkStarted":                        int64(10),
+			"countDonorMoveChunkCommitted":                      int64(7),
+			"countDonorMoveChunkAborted":                        int64(2),
+			"countDonorMoveChunkLockTimeout":                    int64(1),
+			"countDonorMoveChunkAbortConflictingIndexOperation": int64(1),
+			"totalDonorChunkCloneTimeMillis":                    int64(1500),
+			"totalCriticalSectionCommitTimeMillis":              int64(200),
+			"totalCriticalSectionTimeMillis":                    int64(800),
+			"countDocsClonedOnDonor":                            int64(4000),
+			"rangeDeleterTasks":                                 int32(1),
+			"catalogCache": bson.M{
+				"numDatabaseEntries":               int64(2),
+				"numCollectionEntries":             int64(5),
+				"countIncrementalRefreshesStarted": int64(12),
+				"countFullRefreshesStarted":        int64(3),
+				"countFailedRefreshes":             int64(1),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serverStatus := &ServerStatus{}
+	loadServerStatusFromBson(data, serverStatus)
+
+	stats := serverStatus.ShardingStatistics
+	if stats == nil {
+		t.Fatal("ShardingStatistics group was not loaded")
+	}
+	if stats.CountDonorMoveChunkStarted != 10 || stats.CountDonorMoveChunkCommitted != 7 || stats.CountDonorMoveChunkAborted != 2 {
+		t.Errorf("donor migration counters were not loaded: %+v", stats)
+	}
+	if stats.CountDonorMoveChunkLockTimeout != 1 || stats.CountDonorMoveChunkAbortConflictingIndexOperation != 1 {
+		t.Errorf("donor abort causes were not loaded: %+v", stats)
+	}
+	if stats.TotalCriticalSectionTimeMillis != 800 || stats.TotalCriticalSectionCommitTimeMillis != 200 || stats.RangeDeleterTasks != 1 {
+		t.Errorf("critical section and range deleter stats were not loaded: %+v", stats)
+	}
+
+	cache := stats.CatalogCache
+	if cache == nil {
+		t.Fatal("CatalogCache group was not loaded")
+	}
+	if cache.NumCollectionEntries != 5 || cache.CountIncrementalRefreshesStarted != 12 || cache.CountFullRefreshesStarted != 3 || cache.CountFailedRefreshes != 1 {
+		t.Errorf("catalog cache stats were not loaded: %+v", cache)
+	}
+}
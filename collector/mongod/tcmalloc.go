@@ -0,0 +1,180 @@
+package collector_mongod
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	tcmallocGenericBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "tcmalloc",
+		Name:      "generic_bytes",
+		Help:      "The generic allocator properties reported by tcmalloc: bytes allocated by the application and total heap size",
+	}, []string{"type"})
+	tcmallocBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "tcmalloc",
+		Name:      "bytes",
+		Help:      "The tcmalloc pageheap, thread cache and free list sizes in bytes",
+	}, []string{"type"})
+	tcmallocPageheapOperationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "tcmalloc",
+		Name:      "pageheap_operations_total",
+		Help:      "The total number of tcmalloc pageheap scavenge, commit, decommit and reserve operations",
+	}, []string{"type"})
+	tcmallocFragmentationRatio = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "tcmalloc",
+		Name:      "fragmentation_ratio",
+		Help:      "The fraction of the tcmalloc heap that is not allocated by the application",
+	})
+	tcmallocAggressiveMemoryDecommit = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "tcmalloc",
+		Name:      "aggressive_memory_decommit",
+		Help:      "Whether tcmalloc aggressively decommits freed memory (1 = enabled/0 = disabled)",
+	})
+	tcmallocSpinlockDelaySecondsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "tcmalloc",
+		Name:      "spinlock_delay_seconds_total",
+		Help:      "The total time in seconds tcmalloc has spent waiting on spinlocks",
+	})
+	tcmallocSizeClassBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "tcmalloc",
+		Name:      "size_class_bytes",
+		Help:      "The free and allocated bytes per tcmalloc size class (requires tcmalloc verbosity 2)",
+	}, []string{"size", "type"})
+	tcmallocSizeClassObjects = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "tcmalloc",
+		Name:      "size_class_objects",
+		Help:      "The number of free objects per tcmalloc size class and cache (requires tcmalloc verbosity 2)",
+	}, []string{"size", "cache"})
+)
+
+// TCMallocGenericStats keeps the allocator independent memory properties
+type TCMallocGenericStats struct {
+	CurrentAllocatedBytes float64 `bson:"current_allocated_bytes"`
+	HeapSize              float64 `bson:"heap_size"`
+}
+
+// fragmentationRatio returns the fraction of the heap that is not allocated
+// by the application, or false when the heap size is unknown
+func (stats *TCMallocGenericStats) fragmentationRatio() (float64, bool) {
+	if stats.HeapSize <= 0 {
+		return 0, false
+	}
+	return (stats.HeapSize - stats.CurrentAllocatedBytes) / stats.HeapSize, true
+}
+
+// TCMallocSizeClass is an element of the per-size-class breakdown
+type TCMallocSizeClass struct {
+	BytesPerObject  float64 `bson:"bytes_per_object"`
+	PagesPerSpan    float64 `bson:"pages_per_span"`
+	NumSpans        float64 `bson:"num_spans"`
+	NumThreadObjs   float64 `bson:"num_thread_objs"`
+	NumCentralObjs  float64 `bson:"num_central_objs"`
+	NumTransferObjs float64 `bson:"num_transfer_objs"`
+	FreeBytes       float64 `bson:"free_bytes"`
+	AllocatedBytes  float64 `bson:"allocated_bytes"`
+}
+
+// TCMallocDetailStats keeps the tcmalloc specific properties
+type TCMallocDetailStats struct {
+	PageheapFreeBytes             float64             `bson:"pageheap_free_bytes"`
+	PageheapUnmappedBytes         float64             `bson:"pageheap_unmapped_bytes"`
+	PageheapCommittedBytes        float64             `bson:"pageheap_committed_bytes"`
+	PageheapScavengeCount         float64             `bson:"pageheap_scavenge_count"`
+	PageheapCommitCount           float64             `bson:"pageheap_commit_count"`
+	PageheapDecommitCount         float64             `bson:"pageheap_decommit_count"`
+	PageheapReserveCount          float64             `bson:"pageheap_reserve_count"`
+	MaxTotalThreadCacheBytes      float64             `bson:"max_total_thread_cache_bytes"`
+	CurrentTotalThreadCacheBytes  float64             `bson:"current_total_thread_cache_bytes"`
+	TotalFreeBytes                float64             `bson:"total_free_bytes"`
+	CentralCacheFreeBytes         float64             `bson:"central_cache_free_bytes"`
+	TransferCacheFreeBytes        float64             `bson:"transfer_cache_free_bytes"`
+	ThreadCacheFreeBytes          float64             `bson:"thread_cache_free_bytes"`
+	AggressiveMemoryDecommit      float64             `bson:"aggressive_memory_decommit"`
+	SpinlockTotalDelayNanoseconds float64             `bson:"spinlock_total_delay_ns"`
+	SizeClasses                   []TCMallocSizeClass `bson:"size_classes,omitempty"`
+}
+
+// TCMallocStats keeps the data of the serverStatus tcmalloc section
+type TCMallocStats struct {
+	Generic  *TCMallocGenericStats `bson:"generic"`
+	TCMalloc *TCMallocDetailStats  `bson:"tcmalloc"`
+}
+
+// Export exports the data to prometheus.
+func (stats *TCMallocStats) Export(ch chan<- prometheus.Metric) {
+	tcmallocSizeClassBytes.Reset()
+	tcmallocSizeClassObjects.Reset()
+
+	// the fragmentation ratio is not collected without a heap size, rather
+	// than keeping the value of a previous scrape
+	hasFragmentationRatio := false
+	if stats.Generic != nil {
+		tcmallocGenericBytes.WithLabelValues("current_allocated").Set(stats.Generic.CurrentAllocatedBytes)
+		tcmallocGenericBytes.WithLabelValues("heap_size").Set(stats.Generic.HeapSize)
+		if ratio, ok := stats.Generic.fragmentationRatio(); ok {
+			tcmallocFragmentationRatio.Set(ratio)
+			hasFragmentationRatio = true
+		}
+	}
+
+	if detail := stats.TCMalloc; detail != nil {
+		tcmallocBytes.WithLabelValues("pageheap_free").Set(detail.PageheapFreeBytes)
+		tcmallocBytes.WithLabelValues("pageheap_unmapped").Set(detail.PageheapUnmappedBytes)
+		tcmallocBytes.WithLabelValues("pageheap_committed").Set(detail.PageheapCommittedBytes)
+		tcmallocBytes.WithLabelValues("max_total_thread_cache").Set(detail.MaxTotalThreadCacheBytes)
+		tcmallocBytes.WithLabelValues("current_total_thread_cache").Set(detail.CurrentTotalThreadCacheBytes)
+		tcmallocBytes.WithLabelValues("total_free").Set(detail.TotalFreeBytes)
+		tcmallocBytes.WithLabelValues("central_cache_free").Set(detail.CentralCacheFreeBytes)
+		tcmallocBytes.WithLabelValues("transfer_cache_free").Set(detail.TransferCacheFreeBytes)
+		tcmallocBytes.WithLabelValues("thread_cache_free").Set(detail.ThreadCacheFreeBytes)
+		tcmallocPageheapOperationsTotal.WithLabelValues("scavenge").Set(detail.PageheapScavengeCount)
+		tcmallocPageheapOperationsTotal.WithLabelValues("commit").Set(detail.PageheapCommitCount)
+		tcmallocPageheapOperationsTotal.WithLabelValues("decommit").Set(detail.PageheapDecommitCount)
+		tcmallocPageheapOperationsTotal.WithLabelValues("reserve").Set(detail.PageheapReserveCount)
+		tcmallocAggressiveMemoryDecommit.Set(detail.AggressiveMemoryDecommit)
+		tcmallocSpinlockDelaySecondsTotal.Set(detail.SpinlockTotalDelayNanoseconds / 1000000000)
+
+		// size_classes is only returned with a tcmalloc verbosity of 2
+		for _, class := range detail.SizeClasses {
+			size := strconv.FormatFloat(class.BytesPerObject, 'f', -1, 64)
+			tcmallocSizeClassBytes.WithLabelValues(size, "free").Set(class.FreeBytes)
+			tcmallocSizeClassBytes.WithLabelValues(size, "allocated").Set(class.AllocatedBytes)
+			tcmallocSizeClassObjects.WithLabelValues(size, "thread").Set(class.NumThreadObjs)
+			tcmallocSizeClassObjects.WithLabelValues(size, "central").Set(class.NumCentralObjs)
+			tcmallocSizeClassObjects.WithLabelValues(size, "transfer").Set(class.NumTransferObjs)
+		}
+	}
+
+	tcmallocGenericBytes.Collect(ch)
+	tcmallocBytes.Collect(ch)
+	tcmallocPageheapOperationsTotal.Collect(ch)
+	if hasFragmentationRatio {
+		tcmallocFragmentationRatio.Collect(ch)
+	}
+	tcmallocAggressiveMemoryDecommit.Collect(ch)
+	tcmallocSpinlockDelaySecondsTotal.Collect(ch)
+	tcmallocSizeClassBytes.Collect(ch)
+	tcmallocSizeClassObjects.Collect(ch)
+}
+
+// Describe describes the metrics for prometheus
+func (stats *TCMallocStats) Describe(ch chan<- *prometheus.Desc) {
+	tcmallocGenericBytes.Describe(ch)
+	tcmallocBytes.Describe(ch)
+	tcmallocPageheapOperationsTotal.Describe(ch)
+	tcmallocFragmentationRatio.Describe(ch)
+	tcmallocAggressiveMemoryDecommit.Describe(ch)
+	tcmallocSpinlockDelaySecondsTotal.Describe(ch)
+	tcmallocSizeClassBytes.Describe(ch)
+	tcmallocSizeClassObjects.Describe(ch)
+}
@@ -0,0 +1,56 @@
+package collector_mongod
+
+import (
+	"testing"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func Test_ParserTCMalloc(t *testing.T) {
+	data, err := bson.Marshal(bson.M{
+		"host": "rs1:27017",
+		"tcmalloc": bson.M{
+			"generic": bson.M{
+				"current_allocated_bytes": int64(750),
+				"heap_size":               int64(1000),
+			},
+			"tcmalloc": bson.M{
+				"pageheap_free_bytes":        int64(100),
+				"pageheap_scavenge_count":    int64(12),
+				"pageheap_commit_count":      int64(40),
+				"spinlock_total_delay_ns":    int64(2500000000),
+				"formattedString":            "------------------------------------------------\nMALLOC: ...",
+				"aggressive_memory_decommit": int32(0),
+				"size_classes": []bson.M{
+					{"bytes_per_object": int32(8), "num_thread_objs": int32(10), "free_bytes": int64(80), "allocated_bytes": int64(8192)},
+					{"bytes_per_object": int32(16), "num_central_objs": int32(4), "free_bytes": int64(64), "allocated_bytes": int64(8192)},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serverStatus := &ServerStatus{}
+	loadServerStatusFromBson(data, serverStatus)
+
+	stats := serverStatus.TCMalloc
+	if stats == nil || stats.Generic == nil || stats.TCMalloc == nil {
+		t.Fatal("TCMalloc group was not loaded")
+	}
+	if ratio, ok := stats.Generic.fragmentationRatio(); !ok || ratio != 0.25 {
+		t.Errorf("Expected a fragmentation ratio of 0.25, got %v", ratio)
+	}
+	detail := stats.TCMalloc
+	if detail.PageheapScavengeCount != 12 || detail.PageheapCommitCount != 40 || detail.SpinlockTotalDelayNanoseconds != 2500000000 {
+		t.Errorf("tcmalloc counters were not loaded: %+v", detail)
+	}
+	if len(detail.SizeClasses) != 2 || detail.SizeClasses[1].BytesPerObject != 16 || detail.SizeClasses[1].NumCentralObjs != 4 {
+		t.Errorf("size classes were not loaded: %+v", detail.SizeClasses)
+	}
+
+	if _, ok := (&TCMallocGenericStats{CurrentAllocatedBytes: 10}).fragmentationRatio(); ok {
+		t.Error("Expected no fragmentation ratio without a heap size")
+	}
+}
@@ -0,0 +1,211 @@
+package collector_mongod
+
+import (
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+var (
+	transactionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "transactions",
+		Name:      "total",
+		Help:      "The total number of multi-document transactions started, committed, aborted and prepared since the server started",
+	}, []string{"type"})
+	transactionsCurrent = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "transactions",
+		Name:      "current",
+		Help:      "The number of currently open, active, inactive and prepared multi-document transactions",
+	}, []string{"state"})
+	transactionsRetriedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "transactions",
+		Name:      "retried_total",
+		Help:      "The total number of retried retryable write commands and statements",
+	}, []string{"type"})
+	transactionsCollectionWritesTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "transactions",
+		Name:      "collection_writes_total",
+		Help:      "The total number of writes to the config.transactions collection",
+	})
+	transactionsOldestOpenSeconds = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "transactions",
+		Name:      "oldest_open_seconds",
+		Help:      "The age in seconds of the oldest open multi-document transaction reported by currentOp",
+	})
+)
+
+var (
+	logicalSessionsActive = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "logical_sessions",
+		Name:      "active",
+		Help:      "The number of active logical sessions cached in memory",
+	})
+	logicalSessionsJobsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "logical_sessions",
+		Name:      "jobs_total",
+		Help:      "The total number of times the sessions collection refresh and transaction reaper jobs have run",
+	}, []string{"job"})
+	logicalSessionsLastJobDurationSeconds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "logical_sessions",
+		Name:      "last_job_duration_seconds",
+		Help:      "The duration in seconds of the last run of the sessions collection refresh and transaction reaper jobs",
+	}, []string{"job"})
+	logicalSessionsLastJobTimestamp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "logical_sessions",
+		Name:      "last_job_timestamp",
+		Help:      "The unix timestamp of the last run of the sessions collection refresh and transaction reaper jobs",
+	}, []string{"job"})
+	logicalSessionsLastJobEntries = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "logical_sessions",
+		Name:      "last_job_entries",
+		Help:      "The number of sessions refreshed, ended, cursors closed and transaction entries cleaned up by the last job runs",
+	}, []string{"type"})
+)
+
+// TransactionStats keeps the data of the serverStatus transactions section (4.0+)
+type TransactionStats struct {
+	RetriedCommandsCount             float64 `bson:"retriedCommandsCount"`
+	RetriedStatementsCount           float64 `bson:"retriedStatementsCount"`
+	TransactionsCollectionWriteCount float64 `bson:"transactionsCollectionWriteCount"`
+	CurrentActive                    float64 `bson:"currentActive"`
+	CurrentInactive                  float64 `bson:"currentInactive"`
+	CurrentOpen                      float64 `bson:"currentOpen"`
+	CurrentPrepared                  float64 `bson:"currentPrepared"`
+	TotalStarted                     float64 `bson:"totalStarted"`
+	TotalCommitted                   float64 `bson:"totalCommitted"`
+	TotalAborted                     float64 `bson:"totalAborted"`
+	TotalPrepared                    float64 `bson:"totalPrepared"`
+	TotalPreparedThenCommitted       float64 `bson:"totalPreparedThenCommitted"`
+	TotalPreparedThenAborted         float64 `bson:"totalPreparedThenAborted"`
+
+	// OldestOpenSeconds is filled from currentOp, see GetOldestOpenTransactionSeconds
+	OldestOpenSeconds *float64 `bson:"-"`
+}
+
+// Export exports the data to prometheus.
+func (stats *TransactionStats) Export(ch chan<- prometheus.Metric) {
+	transactionsTotal.WithLabelValues("started").Set(stats.TotalStarted)
+	transactionsTotal.WithLabelValues("committed").Set(stats.TotalCommitted)
+	transactionsTotal.WithLabelValues("aborted").Set(stats.TotalAborted)
+	transactionsTotal.WithLabelValues("prepared").Set(stats.TotalPrepared)
+	transactionsTotal.WithLabelValues("prepared_then_committed").Set(stats.TotalPreparedThenCommitted)
+	transactionsTotal.WithLabelValues("prepared_then_aborted").Set(stats.TotalPreparedThenAborted)
+	transactionsCurrent.WithLabelValues("open").Set(stats.CurrentOpen)
+	transactionsCurrent.WithLabelValues("active").Set(stats.CurrentActive)
+	transactionsCurrent.WithLabelValues("inactive").Set(stats.CurrentInactive)
+	transactionsCurrent.WithLabelValues("prepared").Set(stats.CurrentPrepared)
+	transactionsRetriedTotal.WithLabelValues("commands").Set(stats.RetriedCommandsCount)
+	transactionsRetriedTotal.WithLabelValues("statements").Set(stats.RetriedStatementsCount)
+	transactionsCollectionWritesTotal.Set(stats.TransactionsCollectionWriteCount)
+
+	transactionsTotal.Collect(ch)
+	transactionsCurrent.Collect(ch)
+	transactionsRetriedTotal.Collect(ch)
+	transactionsCollectionWritesTotal.Collect(ch)
+
+	if stats.OldestOpenSeconds != nil {
+		transactionsOldestOpenSeconds.Set(*stats.OldestOpenSeconds)
+		transactionsOldestOpenSeconds.Collect(ch)
+	}
+}
+
+// Describe describes the metrics for prometheus
+func (stats *TransactionStats) Describe(ch chan<- *prometheus.Desc) {
+	transactionsTotal.Describe(ch)
+	transactionsCurrent.Describe(ch)
+	transactionsRetriedTotal.Describe(ch)
+	transactionsCollectionWritesTotal.Describe(ch)
+	transactionsOldestOpenSeconds.Describe(ch)
+}
+
+// LogicalSessionStats keeps the data of the serverStatus logicalSessionRecordCache section (3.6+)
+type LogicalSessionStats struct {
+	ActiveSessionsCount                       float64    `bson:"activeSessionsCount"`
+	SessionsCollectionJobCount                float64    `bson:"sessionsCollectionJobCount"`
+	LastSessionsCollectionJobDurationMillis   float64    `bson:"lastSessionsCollectionJobDurationMillis"`
+	LastSessionsCollectionJobTimestamp        *time.Time `bson:"lastSessionsCollectionJobTimestamp"`
+	LastSessionsCollectionJobEntriesRefreshed float64    `bson:"lastSessionsCollectionJobEntriesRefreshed"`
+	LastSessionsCollectionJobEntriesEnded     float64    `bson:"lastSessionsCollectionJobEntriesEnded"`
+	LastSessionsCollectionJobCursorsClosed    float64    `bson:"lastSessionsCollectionJobCursorsClosed"`
+	TransactionReaperJobCount                 float64    `bson:"transactionReaperJobCount"`
+	LastTransactionReaperJobDurationMillis    float64    `bson:"lastTransactionReaperJobDurationMillis"`
+	LastTransactionReaperJobTimestamp         *time.Time `bson:"lastTransactionReaperJobTimestamp"`
+	LastTransactionReaperJobEntriesCleanedUp  float64    `bson:"lastTransactionReaperJobEntriesCleanedUp"`
+}
+
+// Export exports the data to prometheus.
+func (stats *LogicalSessionStats) Export(ch chan<- prometheus.Metric) {
+	logicalSessionsLastJobTimestamp.Reset()
+
+	logicalSessionsActive.Set(stats.ActiveSessionsCount)
+	logicalSessionsJobsTotal.WithLabelValues("sessions_collection").Set(stats.SessionsCollectionJobCount)
+	logicalSessionsJobsTotal.WithLabelValues("transaction_reaper").Set(stats.TransactionReaperJobCount)
+	logicalSessionsLastJobDurationSeconds.WithLabelValues("sessions_collection").Set(stats.LastSessionsCollectionJobDurationMillis / 1000)
+	logicalSessionsLastJobDurationSeconds.WithLabelValues("transaction_reaper").Set(stats.LastTransactionReaperJobDurationMillis / 1000)
+	// the job timestamps are missing until the jobs have run once
+	if stats.LastSessionsCollectionJobTimestamp != nil {
+		logicalSessionsLastJobTimestamp.WithLabelValues("sessions_collection").Set(float64(stats.LastSessionsCollectionJobTimestamp.Unix()))
+	}
+	if stats.LastTransactionReaperJobTimestamp != nil {
+		logicalSessionsLastJobTimestamp.WithLabelValues("transaction_reaper").Set(float64(stats.LastTransactionReaperJobTimestamp.Unix()))
+	}
+	logicalSessionsLastJobEntries.WithLabelValues("refreshed").Set(stats.LastSessionsCollectionJobEntriesRefreshed)
+	logicalSessionsLastJobEntries.WithLabelValues("ended").Set(stats.LastSessionsCollectionJobEntriesEnded)
+	logicalSessionsLastJobEntries.WithLabelValues("cursors_closed").Set(stats.LastSessionsCollectionJobCursorsClosed)
+	logicalSessionsLastJobEntries.WithLabelValues("cleaned_up").Set(stats.LastTransactionReaperJobEntriesCleanedUp)
+
+	logicalSessionsActive.Collect(ch)
+	logicalSessionsJobsTotal.Collect(ch)
+	logicalSessionsLastJobDurationSeconds.Collect(ch)
+	logicalSessionsLastJobTimestamp.Collect(ch)
+	logicalSessionsLastJobEntries.Collect(ch)
+}
+
+// Describe describes the metrics for prometheus
+func (stats *LogicalSessionStats) Describe(ch chan<- *prometheus.Desc) {
+	logicalSessionsActive.Describe(ch)
+	logicalSessionsJobsTotal.Describe(ch)
+	logicalSessionsLastJobDurationSeconds.Describe(ch)
+	logicalSessionsLastJobTimestamp.Describe(ch)
+	logicalSessionsLastJobEntries.Describe(ch)
+}
+
+// GetOldestOpenTransactionSeconds returns the age in seconds of the oldest open
+// transaction, using the $currentOp aggregation stage (4.0+). It returns 0 when
+// there is no open transaction.
+func GetOldestOpenTransactionSeconds(session *mgo.Session) (float64, error) {
+	result := struct {
+		Cursor struct {
+			FirstBatch []struct {
+				MaxTimeOpenMicros float64 `bson:"maxTimeOpenMicros"`
+			} `bson:"firstBatch"`
+		} `bson:"cursor"`
+	}{}
+	pipeline := []bson.M{
+		{"$currentOp": bson.M{"allUsers": true, "idleSessions": true}},
+		{"$match": bson.M{"transaction.timeOpenMicros": bson.M{"$exists": true}}},
+		{"$group": bson.M{"_id": nil, "maxTimeOpenMicros": bson.M{"$max": "$transaction.timeOpenMicros"}}},
+	}
+	err := session.DB("admin").Run(bson.D{{"aggregate", 1}, {"pipeline", pipeline}, {"cursor", bson.M{}}}, &result)
+	if err != nil {
+		glog.Errorf("Failed to get open transactions from currentOp: %s", err)
+		return 0, err
+	}
+	if len(result.Cursor.FirstBatch) == 0 {
+		return 0, nil
+	}
+	return result.Cursor.FirstBatch[0].MaxTimeOpenMicros / 1000000, nil
+}
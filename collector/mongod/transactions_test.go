@@ -0,0 +1,56 @@
+package collector_mongod
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func Test_ParserTransactions(t *testing.T) {
+	jobTime := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	data, _ := bson.Marshal(bson.M{
+		"logicalSessionRecordCache": bson.M{
+			"activeSessionsCount":                     int32(4),
+			"sessionsCollectionJobCount":              int32(20),
+			"lastSessionsCollectionJobDurationMillis": int32(15),
+			"lastSessionsCollectionJobTimestamp":      jobTime,
+			"transactionReaperJobCount":               int32(19),
+		},
+		"transactions": bson.M{
+			"retriedCommandsCount":       int64(3),
+			"currentOpen":                int64(2),
+			"currentPrepared":            int64(1),
+			"totalStarted":               int64(100),
+			"totalCommitted":             int64(90),
+			"totalPreparedThenCommitted": int64(5),
+		},
+	})
+
+	status := &ServerStatus{}
+	if err := bson.Unmarshal(data, status); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions := status.LogicalSessionRecordCache
+	if sessions == nil {
+		t.Fatal("LogicalSessionRecordCache group was not loaded")
+	}
+	if sessions.ActiveSessionsCount != 4 || sessions.SessionsCollectionJobCount != 20 || sessions.TransactionReaperJobCount != 19 {
+		t.Errorf("logical session counters were not loaded: %+v", sessions)
+	}
+	if sessions.LastSessionsCollectionJobTimestamp == nil || !sessions.LastSessionsCollectionJobTimestamp.Equal(jobTime) {
+		t.Errorf("Expected the sessions collection job timestamp %v, got %v", jobTime, sessions.LastSessionsCollectionJobTimestamp)
+	}
+	if sessions.LastTransactionReaperJobTimestamp != nil {
+		t.Errorf("Expected no transaction reaper job timestamp, got %v", sessions.LastTransactionReaperJobTimestamp)
+	}
+
+	transactions := status.Transactions
+	if transactions == nil {
+		t.Fatal("Transactions group was not loaded")
+	}
+	if transactions.TotalStarted != 100 || transactions.TotalCommitted != 90 || transactions.CurrentPrepared != 1 || transactions.TotalPreparedThenCommitted != 5 {
+		t.Errorf("transaction counters were not loaded: %+v", transactions)
+	}
+}
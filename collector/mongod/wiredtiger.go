@@ -62,8 +62,225 @@ var (
 		Name:      "overhead_percent",
 		Help:      "The percentage overhead of the WiredTiger Cache",
 	})
+	wtCacheDirtyBytesTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "wiredtiger_cache",
+		Name:      "dirty_bytes_total",
+		Help:      "The cumulative number of bytes dirtied in the WiredTiger Cache",
+	})
+	wtCacheEvictionPagesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "wiredtiger_cache",
+		Name:      "eviction_pages_total",
+		Help:      "The total number of pages evicted from the WiredTiger Cache by the eviction server, worker threads and application threads",
+	}, []string{"thread"})
+	wtCacheEvictionWalkedPagesTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "wiredtiger_cache",
+		Name:      "eviction_walked_pages_total",
+		Help:      "The total number of pages walked by the WiredTiger eviction server looking for candidates",
+	})
+	wtCacheEvictionFailedPagesTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "wiredtiger_cache",
+		Name:      "eviction_failed_pages_total",
+		Help:      "The total number of pages selected for eviction that could not be evicted from the WiredTiger Cache",
+	})
+	wtCacheEvictionBlockedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "wiredtiger_cache",
+		Name:      "eviction_blocked_total",
+		Help:      "The total number of page evictions blocked in the WiredTiger Cache",
+	}, []string{"reason"})
+	wtCacheEvictionGoalMissedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "wiredtiger_cache",
+		Name:      "eviction_goal_missed_total",
+		Help:      "The total number of times the WiredTiger eviction server was unable to reach its eviction goal",
+	})
+)
+
+var (
+	wtConnectionFilesOpen = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "wiredtiger_connection",
+		Name:      "files_open",
+		Help:      "The number of files currently open by WiredTiger",
+	})
+	wtConnectionMemoryOperationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "wiredtiger_connection",
+		Name:      "memory_operations_total",
+		Help:      "The total number of memory allocations, frees and re-allocations made by WiredTiger",
+	}, []string{"type"})
+	wtConnectionIOTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "wiredtiger_connection",
+		Name:      "io_total",
+		Help:      "The total number of read, write and fsync I/Os issued by WiredTiger",
+	}, []string{"type"})
+	wtConnectionMutexCallsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "wiredtiger_connection",
+		Name:      "pthread_mutex_calls_total",
+		Help:      "The total number of pthread mutex calls made by WiredTiger",
+	}, []string{"type"})
+)
+
+var (
+	wtCursorCallsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "wiredtiger_cursor",
+		Name:      "calls_total",
+		Help:      "The total number of WiredTiger cursor calls by operation",
+	}, []string{"type"})
+	wtCursorRestartedSearchesTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "wiredtiger_cursor",
+		Name:      "restarted_searches_total",
+		Help:      "The total number of WiredTiger cursor searches that had to be restarted",
+	})
+	wtCursorCached = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "wiredtiger_cursor",
+		Name:      "cached",
+		Help:      "The number of cursors currently cached by WiredTiger",
+	})
+)
+
+var (
+	wtDataHandlesActive = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "wiredtiger_data_handle",
+		Name:      "active",
+		Help:      "The number of WiredTiger connection data handles currently active",
+	})
+	wtDataHandleSweepDhandlesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "wiredtiger_data_handle",
+		Name:      "sweep_dhandles_total",
+		Help:      "The total number of WiredTiger data handles processed by the sweep server",
+	}, []string{"type"})
+	wtDataHandleSweepsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "wiredtiger_data_handle",
+		Name:      "sweeps_total",
+		Help:      "The total number of WiredTiger connection sweeps and session sweep attempts",
+	}, []string{"type"})
+)
+
+var (
+	wtReconciliationCallsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "wiredtiger_reconciliation",
+		Name:      "calls_total",
+		Help:      "The total number of WiredTiger page reconciliation calls",
+	}, []string{"type"})
+	wtReconciliationPagesDeletedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "wiredtiger_reconciliation",
+		Name:      "pages_deleted_total",
+		Help:      "The total number of pages deleted by WiredTiger reconciliation",
+	}, []string{"type"})
+	wtReconciliationSplitAwaitingFreeBytes = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "wiredtiger_reconciliation",
+		Name:      "split_awaiting_free_bytes",
+		Help:      "The number of bytes from WiredTiger page splits currently awaiting free",
+	})
+	wtReconciliationSplitAwaitingFreeObjects = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "wiredtiger_reconciliation",
+		Name:      "split_awaiting_free_objects",
+		Help:      "The number of objects from WiredTiger page splits currently awaiting free",
+	})
+)
+
+var (
+	wtThreadYieldPageAcquireBlockedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "wiredtiger_thread_yield",
+		Name:      "page_acquire_blocked_total",
+		Help:      "The total number of times a WiredTiger thread was blocked acquiring a page",
+	}, []string{"type"})
+	wtThreadYieldPageAcquireSleepSecondsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "wiredtiger_thread_yield",
+		Name:      "page_acquire_sleep_seconds_total",
+		Help:      "The total time in seconds WiredTiger threads have slept while acquiring a page",
+	})
+	wtThreadStateActiveCalls = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "wiredtiger_thread_state",
+		Name:      "active_filesystem_calls",
+		Help:      "The number of WiredTiger filesystem calls currently in progress",
+	}, []string{"type"})
+)
+
+var (
+	wtLSMWorkUnitsQueued = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "wiredtiger_lsm",
+		Name:      "work_units_queued",
+		Help:      "The number of WiredTiger LSM work units currently queued",
+	}, []string{"type"})
+	wtLSMRowsMergedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "wiredtiger_lsm",
+		Name:      "rows_merged_total",
+		Help:      "The total number of rows merged in WiredTiger LSM trees",
+	})
+	wtLSMThrottleSleepsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "wiredtiger_lsm",
+		Name:      "throttle_sleeps_total",
+		Help:      "The total number of WiredTiger LSM checkpoint and merge throttle sleeps",
+	}, []string{"type"})
+	wtLSMTreeMaintenanceOperationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "wiredtiger_lsm",
+		Name:      "tree_maintenance_operations_total",
+		Help:      "The total number of WiredTiger LSM tree maintenance operations",
+	}, []string{"type"})
+	wtLSMTreeQueueMaxHitsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "wiredtiger_lsm",
+		Name:      "tree_queue_max_hits_total",
+		Help:      "The total number of times the WiredTiger LSM tree queue hit its maximum",
+	})
+)
+
+var (
+	wtLockAcquisitionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "wiredtiger_lock",
+		Name:      "acquisitions_total",
+		Help:      "The total number of WiredTiger lock acquisitions",
+	}, []string{"lock"})
+	wtLockWaitSecondsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "wiredtiger_lock",
+		Name:      "wait_seconds_total",
+		Help:      "The total time in seconds application and internal threads have waited for WiredTiger locks",
+	}, []string{"lock", "thread"})
 )
 
+// wtValue returns the first non-zero value, letting stats that were renamed
+// between WiredTiger releases be read from whichever name the server reports.
+func wtValue(values ...float64) float64 {
+	for _, value := range values {
+		if value != 0 {
+			return value
+		}
+	}
+	return 0
+}
+
+// usecsToSeconds converts a WiredTiger (usecs) stat to seconds.
+func usecsToSeconds(usecs float64) float64 {
+	return usecs / 1000000
+}
+
 var (
 	wtTransactionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Namespace: Namespace,
@@ -196,6 +413,17 @@ type WTCacheStats struct {
 	PagesReadInto      float64 `bson:"pages read into cache"`
 	PagesWrittenFrom   float64 `bson:"pages written from cache"`
 	PagesDirty         float64 `bson:"tracked dirty pages in the cache"`
+
+	BytesDirtyTotal           float64 `bson:"bytes dirty in the cache cumulative"`
+	EvictionServerEvicted     float64 `bson:"eviction server evicting pages"`
+	EvictionWorkerEvicted     float64 `bson:"eviction worker thread evicting pages"`
+	EvictionAppThreadEvicted  float64 `bson:"pages evicted by application threads"`
+	EvictionPagesWalked       float64 `bson:"pages seen by eviction walk"`
+	EvictionPagesWalkedLegacy float64 `bson:"pages walked for eviction"`
+	EvictionFailed            float64 `bson:"pages selected for eviction unable to be evicted"`
+	EvictionBlockedHazard     float64 `bson:"hazard pointer blocked page eviction"`
+	EvictionBlockedCheckpoint float64 `bson:"checkpoint blocked page eviction"`
+	EvictionServerGoalMissed  float64 `bson:"eviction server unable to reach eviction goal"`
 }
 
 func (stats *WTCacheStats) Export(ch chan<- prometheus.Metric) {
@@ -213,15 +441,31 @@ func (stats *WTCacheStats) Export(ch chan<- prometheus.Metric) {
 	wtCacheBytes.WithLabelValues("leaf_pages").Set(stats.BytesLeafPages)
 	wtCacheMaxBytes.Set(stats.MaxBytes)
 	wtCachePercentOverhead.Set(stats.PercentOverhead)
+	wtCacheDirtyBytesTotal.Set(stats.BytesDirtyTotal)
+	wtCacheEvictionPagesTotal.WithLabelValues("server").Set(stats.EvictionServerEvicted)
+	wtCacheEvictionPagesTotal.WithLabelValues("worker").Set(stats.EvictionWorkerEvicted)
+	wtCacheEvictionPagesTotal.WithLabelValues("application").Set(stats.EvictionAppThreadEvicted)
+	wtCacheEvictionWalkedPagesTotal.Set(wtValue(stats.EvictionPagesWalked, stats.EvictionPagesWalkedLegacy))
+	wtCacheEvictionFailedPagesTotal.Set(stats.EvictionFailed)
+	wtCacheEvictionBlockedTotal.WithLabelValues("hazard_pointer").Set(stats.EvictionBlockedHazard)
+	wtCacheEvictionBlockedTotal.WithLabelValues("checkpoint").Set(stats.EvictionBlockedCheckpoint)
+	wtCacheEvictionGoalMissedTotal.Set(stats.EvictionServerGoalMissed)
 }
 
 func (stats *WTCacheStats) Describe(ch chan<- *prometheus.Desc) {
 	wtCachePagesTotal.Describe(ch)
+	wtCacheBytesTotal.Describe(ch)
 	wtCacheEvictedTotal.Describe(ch)
 	wtCachePages.Describe(ch)
 	wtCacheBytes.Describe(ch)
 	wtCacheMaxBytes.Describe(ch)
 	wtCachePercentOverhead.Describe(ch)
+	wtCacheDirtyBytesTotal.Describe(ch)
+	wtCacheEvictionPagesTotal.Describe(ch)
+	wtCacheEvictionWalkedPagesTotal.Describe(ch)
+	wtCacheEvictionFailedPagesTotal.Describe(ch)
+	wtCacheEvictionBlockedTotal.Describe(ch)
+	wtCacheEvictionGoalMissedTotal.Describe(ch)
 }
 
 // log stats
@@ -340,6 +584,248 @@ func (stats *WTConcurrentTransactionsStats) Describe(ch chan<- *prometheus.Desc)
 	wtConcurrentTransactionsTotalTickets.Describe(ch)
 }
 
+// connection stats
+type WTConnectionStats struct {
+	FilesOpen          float64 `bson:"files currently open"`
+	MemoryAllocations  float64 `bson:"memory allocations"`
+	MemoryFrees        float64 `bson:"memory frees"`
+	MemoryReallocs     float64 `bson:"memory re-allocations"`
+	ReadIOs            float64 `bson:"total read I/Os"`
+	WriteIOs           float64 `bson:"total write I/Os"`
+	FsyncIOs           float64 `bson:"total fsync I/Os"`
+	MutexConditionWait float64 `bson:"pthread mutex condition wait calls"`
+	MutexReadLock      float64 `bson:"pthread mutex shared lock read-lock calls"`
+	MutexWriteLock     float64 `bson:"pthread mutex shared lock write-lock calls"`
+}
+
+func (stats *WTConnectionStats) Export(ch chan<- prometheus.Metric) {
+	wtConnectionFilesOpen.Set(stats.FilesOpen)
+	wtConnectionMemoryOperationsTotal.WithLabelValues("allocation").Set(stats.MemoryAllocations)
+	wtConnectionMemoryOperationsTotal.WithLabelValues("free").Set(stats.MemoryFrees)
+	wtConnectionMemoryOperationsTotal.WithLabelValues("reallocation").Set(stats.MemoryReallocs)
+	wtConnectionIOTotal.WithLabelValues("read").Set(stats.ReadIOs)
+	wtConnectionIOTotal.WithLabelValues("write").Set(stats.WriteIOs)
+	wtConnectionIOTotal.WithLabelValues("fsync").Set(stats.FsyncIOs)
+	wtConnectionMutexCallsTotal.WithLabelValues("condition_wait").Set(stats.MutexConditionWait)
+	wtConnectionMutexCallsTotal.WithLabelValues("read_lock").Set(stats.MutexReadLock)
+	wtConnectionMutexCallsTotal.WithLabelValues("write_lock").Set(stats.MutexWriteLock)
+}
+
+func (stats *WTConnectionStats) Describe(ch chan<- *prometheus.Desc) {
+	wtConnectionFilesOpen.Describe(ch)
+	wtConnectionMemoryOperationsTotal.Describe(ch)
+	wtConnectionIOTotal.Describe(ch)
+	wtConnectionMutexCallsTotal.Describe(ch)
+}
+
+// cursor stats
+type WTCursorStats struct {
+	Create            float64 `bson:"cursor create calls"`
+	Insert            float64 `bson:"cursor insert calls"`
+	Modify            float64 `bson:"cursor modify calls"`
+	Next              float64 `bson:"cursor next calls"`
+	Prev              float64 `bson:"cursor prev calls"`
+	Remove            float64 `bson:"cursor remove calls"`
+	Reserve           float64 `bson:"cursor reserve calls"`
+	Reset             float64 `bson:"cursor reset calls"`
+	Search            float64 `bson:"cursor search calls"`
+	SearchNear        float64 `bson:"cursor search near calls"`
+	Update            float64 `bson:"cursor update calls"`
+	RestartedSearches float64 `bson:"cursor restarted searches"`
+	Cached            float64 `bson:"cached cursor count"`
+}
+
+func (stats *WTCursorStats) Export(ch chan<- prometheus.Metric) {
+	wtCursorCallsTotal.WithLabelValues("create").Set(stats.Create)
+	wtCursorCallsTotal.WithLabelValues("insert").Set(stats.Insert)
+	wtCursorCallsTotal.WithLabelValues("modify").Set(stats.Modify)
+	wtCursorCallsTotal.WithLabelValues("next").Set(stats.Next)
+	wtCursorCallsTotal.WithLabelValues("prev").Set(stats.Prev)
+	wtCursorCallsTotal.WithLabelValues("remove").Set(stats.Remove)
+	wtCursorCallsTotal.WithLabelValues("reserve").Set(stats.Reserve)
+	wtCursorCallsTotal.WithLabelValues("reset").Set(stats.Reset)
+	wtCursorCallsTotal.WithLabelValues("search").Set(stats.Search)
+	wtCursorCallsTotal.WithLabelValues("search_near").Set(stats.SearchNear)
+	wtCursorCallsTotal.WithLabelValues("update").Set(stats.Update)
+	wtCursorRestartedSearchesTotal.Set(stats.RestartedSearches)
+	wtCursorCached.Set(stats.Cached)
+}
+
+func (stats *WTCursorStats) Describe(ch chan<- *prometheus.Desc) {
+	wtCursorCallsTotal.Describe(ch)
+	wtCursorRestartedSearchesTotal.Describe(ch)
+	wtCursorCached.Describe(ch)
+}
+
+// data-handle stats
+type WTDataHandleStats struct {
+	Active            float64 `bson:"connection data handles currently active"`
+	SweepClosed       float64 `bson:"connection sweep dhandles closed"`
+	SweepClosedLegacy float64 `bson:"connection dhandles swept"`
+	SweepRemoved      float64 `bson:"connection sweep dhandles removed from hash list"`
+	SweepReferenced   float64 `bson:"connection sweep candidate became referenced"`
+	SweepTimeOfDeath  float64 `bson:"connection sweep time-of-death sets"`
+	SessionSwept      float64 `bson:"session dhandles swept"`
+	Sweeps            float64 `bson:"connection sweeps"`
+	SessionSweeps     float64 `bson:"session sweep attempts"`
+}
+
+func (stats *WTDataHandleStats) Export(ch chan<- prometheus.Metric) {
+	wtDataHandlesActive.Set(stats.Active)
+	wtDataHandleSweepDhandlesTotal.WithLabelValues("closed").Set(wtValue(stats.SweepClosed, stats.SweepClosedLegacy))
+	wtDataHandleSweepDhandlesTotal.WithLabelValues("removed").Set(stats.SweepRemoved)
+	wtDataHandleSweepDhandlesTotal.WithLabelValues("referenced").Set(stats.SweepReferenced)
+	wtDataHandleSweepDhandlesTotal.WithLabelValues("time_of_death").Set(stats.SweepTimeOfDeath)
+	wtDataHandleSweepDhandlesTotal.WithLabelValues("session").Set(stats.SessionSwept)
+	wtDataHandleSweepsTotal.WithLabelValues("connection").Set(stats.Sweeps)
+	wtDataHandleSweepsTotal.WithLabelValues("session").Set(stats.SessionSweeps)
+}
+
+func (stats *WTDataHandleStats) Describe(ch chan<- *prometheus.Desc) {
+	wtDataHandlesActive.Describe(ch)
+	wtDataHandleSweepDhandlesTotal.Describe(ch)
+	wtDataHandleSweepsTotal.Describe(ch)
+}
+
+// reconciliation stats
+type WTReconciliationStats struct {
+	PageReconciliations         float64 `bson:"page reconciliation calls"`
+	PageReconciliationsEviction float64 `bson:"page reconciliation calls for eviction"`
+	PagesDeleted                float64 `bson:"pages deleted"`
+	PagesDeletedFastPath        float64 `bson:"fast-path pages deleted"`
+	SplitBytesAwaitingFree      float64 `bson:"split bytes currently awaiting free"`
+	SplitObjectsAwaitingFree    float64 `bson:"split objects currently awaiting free"`
+}
+
+func (stats *WTReconciliationStats) Export(ch chan<- prometheus.Metric) {
+	wtReconciliationCallsTotal.WithLabelValues("total").Set(stats.PageReconciliations)
+	wtReconciliationCallsTotal.WithLabelValues("eviction").Set(stats.PageReconciliationsEviction)
+	wtReconciliationPagesDeletedTotal.WithLabelValues("regular").Set(stats.PagesDeleted)
+	wtReconciliationPagesDeletedTotal.WithLabelValues("fast_path").Set(stats.PagesDeletedFastPath)
+	wtReconciliationSplitAwaitingFreeBytes.Set(stats.SplitBytesAwaitingFree)
+	wtReconciliationSplitAwaitingFreeObjects.Set(stats.SplitObjectsAwaitingFree)
+}
+
+func (stats *WTReconciliationStats) Describe(ch chan<- *prometheus.Desc) {
+	wtReconciliationCallsTotal.Describe(ch)
+	wtReconciliationPagesDeletedTotal.Describe(ch)
+	wtReconciliationSplitAwaitingFreeBytes.Describe(ch)
+	wtReconciliationSplitAwaitingFreeObjects.Describe(ch)
+}
+
+// thread-yield stats
+type WTThreadYieldStats struct {
+	PageAcquireBusyBlocked     float64 `bson:"page acquire busy blocked"`
+	PageAcquireEvictionBlocked float64 `bson:"page acquire eviction blocked"`
+	PageAcquireLockedBlocked   float64 `bson:"page acquire locked blocked"`
+	PageAcquireReadBlocked     float64 `bson:"page acquire read blocked"`
+	PageAcquireSleepUsecs      float64 `bson:"page acquire time sleeping (usecs)"`
+}
+
+func (stats *WTThreadYieldStats) Export(ch chan<- prometheus.Metric) {
+	wtThreadYieldPageAcquireBlockedTotal.WithLabelValues("busy").Set(stats.PageAcquireBusyBlocked)
+	wtThreadYieldPageAcquireBlockedTotal.WithLabelValues("eviction").Set(stats.PageAcquireEvictionBlocked)
+	wtThreadYieldPageAcquireBlockedTotal.WithLabelValues("locked").Set(stats.PageAcquireLockedBlocked)
+	wtThreadYieldPageAcquireBlockedTotal.WithLabelValues("read").Set(stats.PageAcquireReadBlocked)
+	wtThreadYieldPageAcquireSleepSecondsTotal.Set(usecsToSeconds(stats.PageAcquireSleepUsecs))
+}
+
+func (stats *WTThreadYieldStats) Describe(ch chan<- *prometheus.Desc) {
+	wtThreadYieldPageAcquireBlockedTotal.Describe(ch)
+	wtThreadYieldPageAcquireSleepSecondsTotal.Describe(ch)
+}
+
+// thread-state stats
+type WTThreadStateStats struct {
+	ActiveFsyncCalls float64 `bson:"active filesystem fsync calls"`
+	ActiveReadCalls  float64 `bson:"active filesystem read calls"`
+	ActiveWriteCalls float64 `bson:"active filesystem write calls"`
+}
+
+func (stats *WTThreadStateStats) Export(ch chan<- prometheus.Metric) {
+	wtThreadStateActiveCalls.WithLabelValues("fsync").Set(stats.ActiveFsyncCalls)
+	wtThreadStateActiveCalls.WithLabelValues("read").Set(stats.ActiveReadCalls)
+	wtThreadStateActiveCalls.WithLabelValues("write").Set(stats.ActiveWriteCalls)
+}
+
+func (stats *WTThreadStateStats) Describe(ch chan<- *prometheus.Desc) {
+	wtThreadStateActiveCalls.Describe(ch)
+}
+
+// LSM stats
+type WTLSMStats struct {
+	ApplicationWorkUnitsQueued float64 `bson:"application work units currently queued"`
+	MergeWorkUnitsQueued       float64 `bson:"merge work units currently queued"`
+	SwitchWorkUnitsQueued      float64 `bson:"switch work units currently queued"`
+	RowsMerged                 float64 `bson:"rows merged in an LSM tree"`
+	CheckpointThrottleSleeps   float64 `bson:"sleep for LSM checkpoint throttle"`
+	MergeThrottleSleeps        float64 `bson:"sleep for LSM merge throttle"`
+	TreeMaintenanceScheduled   float64 `bson:"tree maintenance operations scheduled"`
+	TreeMaintenanceExecuted    float64 `bson:"tree maintenance operations executed"`
+	TreeMaintenanceDiscarded   float64 `bson:"tree maintenance operations discarded"`
+	TreeQueueMaxHits           float64 `bson:"tree queue hit maximum"`
+}
+
+func (stats *WTLSMStats) Export(ch chan<- prometheus.Metric) {
+	wtLSMWorkUnitsQueued.WithLabelValues("application").Set(stats.ApplicationWorkUnitsQueued)
+	wtLSMWorkUnitsQueued.WithLabelValues("merge").Set(stats.MergeWorkUnitsQueued)
+	wtLSMWorkUnitsQueued.WithLabelValues("switch").Set(stats.SwitchWorkUnitsQueued)
+	wtLSMRowsMergedTotal.Set(stats.RowsMerged)
+	wtLSMThrottleSleepsTotal.WithLabelValues("checkpoint").Set(stats.CheckpointThrottleSleeps)
+	wtLSMThrottleSleepsTotal.WithLabelValues("merge").Set(stats.MergeThrottleSleeps)
+	wtLSMTreeMaintenanceOperationsTotal.WithLabelValues("scheduled").Set(stats.TreeMaintenanceScheduled)
+	wtLSMTreeMaintenanceOperationsTotal.WithLabelValues("executed").Set(stats.TreeMaintenanceExecuted)
+	wtLSMTreeMaintenanceOperationsTotal.WithLabelValues("discarded").Set(stats.TreeMaintenanceDiscarded)
+	wtLSMTreeQueueMaxHitsTotal.Set(stats.TreeQueueMaxHits)
+}
+
+func (stats *WTLSMStats) Describe(ch chan<- *prometheus.Desc) {
+	wtLSMWorkUnitsQueued.Describe(ch)
+	wtLSMRowsMergedTotal.Describe(ch)
+	wtLSMThrottleSleepsTotal.Describe(ch)
+	wtLSMTreeMaintenanceOperationsTotal.Describe(ch)
+	wtLSMTreeQueueMaxHitsTotal.Describe(ch)
+}
+
+// lock stats
+type WTLockStats struct {
+	CheckpointAcquisitions  float64 `bson:"checkpoint lock acquisitions"`
+	CheckpointAppWaitUsecs  float64 `bson:"checkpoint lock application thread wait time (usecs)"`
+	CheckpointIntWaitUsecs  float64 `bson:"checkpoint lock internal thread wait time (usecs)"`
+	MetadataAcquisitions    float64 `bson:"metadata lock acquisitions"`
+	MetadataAppWaitUsecs    float64 `bson:"metadata lock application thread wait time (usecs)"`
+	MetadataIntWaitUsecs    float64 `bson:"metadata lock internal thread wait time (usecs)"`
+	SchemaAcquisitions      float64 `bson:"schema lock acquisitions"`
+	SchemaAppWaitUsecs      float64 `bson:"schema lock application thread wait time (usecs)"`
+	SchemaIntWaitUsecs      float64 `bson:"schema lock internal thread wait time (usecs)"`
+	TableReadAcquisitions   float64 `bson:"table read lock acquisitions"`
+	TableWriteAcquisitions  float64 `bson:"table write lock acquisitions"`
+	TableAcquisitionsLegacy float64 `bson:"table lock acquisitions"`
+	TableAppWaitUsecs       float64 `bson:"table lock application thread time waiting for the table lock (usecs)"`
+	TableIntWaitUsecs       float64 `bson:"table lock internal thread time waiting for the table lock (usecs)"`
+}
+
+func (stats *WTLockStats) Export(ch chan<- prometheus.Metric) {
+	wtLockAcquisitionsTotal.WithLabelValues("checkpoint").Set(stats.CheckpointAcquisitions)
+	wtLockAcquisitionsTotal.WithLabelValues("metadata").Set(stats.MetadataAcquisitions)
+	wtLockAcquisitionsTotal.WithLabelValues("schema").Set(stats.SchemaAcquisitions)
+	// 3.6+ splits the table lock into read and write acquisitions
+	wtLockAcquisitionsTotal.WithLabelValues("table").Set(wtValue(stats.TableReadAcquisitions+stats.TableWriteAcquisitions, stats.TableAcquisitionsLegacy))
+	wtLockWaitSecondsTotal.WithLabelValues("checkpoint", "application").Set(usecsToSeconds(stats.CheckpointAppWaitUsecs))
+	wtLockWaitSecondsTotal.WithLabelValues("checkpoint", "internal").Set(usecsToSeconds(stats.CheckpointIntWaitUsecs))
+	wtLockWaitSecondsTotal.WithLabelValues("metadata", "application").Set(usecsToSeconds(stats.MetadataAppWaitUsecs))
+	wtLockWaitSecondsTotal.WithLabelValues("metadata", "internal").Set(usecsToSeconds(stats.MetadataIntWaitUsecs))
+	wtLockWaitSecondsTotal.WithLabelValues("schema", "application").Set(usecsToSeconds(stats.SchemaAppWaitUsecs))
+	wtLockWaitSecondsTotal.WithLabelValues("schema", "internal").Set(usecsToSeconds(stats.SchemaIntWaitUsecs))
+	wtLockWaitSecondsTotal.WithLabelValues("table", "application").Set(usecsToSeconds(stats.TableAppWaitUsecs))
+	wtLockWaitSecondsTotal.WithLabelValues("table", "internal").Set(usecsToSeconds(stats.TableIntWaitUsecs))
+}
+
+func (stats *WTLockStats) Describe(ch chan<- *prometheus.Desc) {
+	wtLockAcquisitionsTotal.Describe(ch)
+	wtLockWaitSecondsTotal.Describe(ch)
+}
+
 // WiredTiger stats
 type WiredTigerStats struct {
 	BlockManager           *WTBlockManagerStats           `bson:"block-manager"`
@@ -348,6 +834,14 @@ type WiredTigerStats struct {
 	Session                *WTSessionStats                `bson:"session"`
 	Transaction            *WTTransactionStats            `bson:"transaction"`
 	ConcurrentTransactions *WTConcurrentTransactionsStats `bson:"concurrentTransactions"`
+	Connection             *WTConnectionStats             `bson:"connection"`
+	Cursor                 *WTCursorStats                 `bson:"cursor"`
+	DataHandle             *WTDataHandleStats             `bson:"data-handle"`
+	Reconciliation         *WTReconciliationStats         `bson:"reconciliation"`
+	ThreadYield            *WTThreadYieldStats            `bson:"thread-yield"`
+	ThreadState            *WTThreadStateStats            `bson:"thread-state"`
+	LSM                    *WTLSMStats                    `bson:"LSM"`
+	Lock                   *WTLockStats                   `bson:"lock"`
 }
 
 func (stats *WiredTigerStats) Describe(ch chan<- *prometheus.Desc) {
@@ -369,6 +863,30 @@ func (stats *WiredTigerStats) Describe(ch chan<- *prometheus.Desc) {
 	if stats.ConcurrentTransactions != nil {
 		stats.ConcurrentTransactions.Describe(ch)
 	}
+	if stats.Connection != nil {
+		stats.Connection.Describe(ch)
+	}
+	if stats.Cursor != nil {
+		stats.Cursor.Describe(ch)
+	}
+	if stats.DataHandle != nil {
+		stats.DataHandle.Describe(ch)
+	}
+	if stats.Reconciliation != nil {
+		stats.Reconciliation.Describe(ch)
+	}
+	if stats.ThreadYield != nil {
+		stats.ThreadYield.Describe(ch)
+	}
+	if stats.ThreadState != nil {
+		stats.ThreadState.Describe(ch)
+	}
+	if stats.LSM != nil {
+		stats.LSM.Describe(ch)
+	}
+	if stats.Lock != nil {
+		stats.Lock.Describe(ch)
+	}
 }
 
 func (stats *WiredTigerStats) Export(ch chan<- prometheus.Metric) {
@@ -390,6 +908,30 @@ func (stats *WiredTigerStats) Export(ch chan<- prometheus.Metric) {
 	if stats.ConcurrentTransactions != nil {
 		stats.ConcurrentTransactions.Export(ch)
 	}
+	if stats.Connection != nil {
+		stats.Connection.Export(ch)
+	}
+	if stats.Cursor != nil {
+		stats.Cursor.Export(ch)
+	}
+	if stats.DataHandle != nil {
+		stats.DataHandle.Export(ch)
+	}
+	if stats.Reconciliation != nil {
+		stats.Reconciliation.Export(ch)
+	}
+	if stats.ThreadYield != nil {
+		stats.ThreadYield.Export(ch)
+	}
+	if stats.ThreadState != nil {
+		stats.ThreadState.Export(ch)
+	}
+	if stats.LSM != nil {
+		stats.LSM.Export(ch)
+	}
+	if stats.Lock != nil {
+		stats.Lock.Export(ch)
+	}
 
 	wtBlockManagerBlocksTotal.Collect(ch)
 	wtBlockManagerBytesTotal.Collect(ch)
@@ -401,6 +943,12 @@ func (stats *WiredTigerStats) Export(ch chan<- prometheus.Metric) {
 	wtCacheBytes.Collect(ch)
 	wtCacheMaxBytes.Collect(ch)
 	wtCachePercentOverhead.Collect(ch)
+	wtCacheDirtyBytesTotal.Collect(ch)
+	wtCacheEvictionPagesTotal.Collect(ch)
+	wtCacheEvictionWalkedPagesTotal.Collect(ch)
+	wtCacheEvictionFailedPagesTotal.Collect(ch)
+	wtCacheEvictionBlockedTotal.Collect(ch)
+	wtCacheEvictionGoalMissedTotal.Collect(ch)
 
 	wtTransactionsTotal.Collect(ch)
 	wtTransactionsTotalCheckpointMs.Collect(ch)
@@ -418,4 +966,35 @@ func (stats *WiredTigerStats) Export(ch chan<- prometheus.Metric) {
 	wtConcurrentTransactionsOut.Collect(ch)
 	wtConcurrentTransactionsAvailable.Collect(ch)
 	wtConcurrentTransactionsTotalTickets.Collect(ch)
+
+	wtConnectionFilesOpen.Collect(ch)
+	wtConnectionMemoryOperationsTotal.Collect(ch)
+	wtConnectionIOTotal.Collect(ch)
+	wtConnectionMutexCallsTotal.Collect(ch)
+
+	wtCursorCallsTotal.Collect(ch)
+	wtCursorRestartedSearchesTotal.Collect(ch)
+	wtCursorCached.Collect(ch)
+
+	wtDataHandlesActive.Collect(ch)
+	wtDataHandleSweepDhandlesTotal.Collect(ch)
+	wtDataHandleSweepsTotal.Collect(ch)
+
+	wtReconciliationCallsTotal.Collect(ch)
+	wtReconciliationPagesDeletedTotal.Collect(ch)
+	wtReconciliationSplitAwaitingFreeBytes.Collect(ch)
+	wtReconciliationSplitAwaitingFreeObjects.Collect(ch)
+
+	wtThreadYieldPageAcquireBlockedTotal.Collect(ch)
+	wtThreadYieldPageAcquireSleepSecondsTotal.Collect(ch)
+	wtThreadStateActiveCalls.Collect(ch)
+
+	wtLSMWorkUnitsQueued.Collect(ch)
+	wtLSMRowsMergedTotal.Collect(ch)
+	wtLSMThrottleSleepsTotal.Collect(ch)
+	wtLSMTreeMaintenanceOperationsTotal.Collect(ch)
+	wtLSMTreeQueueMaxHitsTotal.Collect(ch)
+
+	wtLockAcquisitionsTotal.Collect(ch)
+	wtLockWaitSecondsTotal.Collect(ch)
 }
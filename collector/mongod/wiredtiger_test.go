@@ -0,0 +1,31 @@
+package collector_mongod
+
+import (
+	"testing"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func Test_ParseWiredTigerRenamedStats(t *testing.T) {
+	legacy, _ := bson.Marshal(bson.M{
+		"data-handle": bson.M{"connection dhandles swept": 7},
+		"cache":       bson.M{"pages walked for eviction": 11},
+	})
+	current, _ := bson.Marshal(bson.M{
+		"data-handle": bson.M{"connection sweep dhandles closed": 7},
+		"cache":       bson.M{"pages seen by eviction walk": 11},
+	})
+
+	for _, data := range [][]byte{legacy, current} {
+		stats := &WiredTigerStats{}
+		if err := bson.Unmarshal(data, stats); err != nil {
+			t.Fatal(err)
+		}
+		if wtValue(stats.DataHandle.SweepClosed, stats.DataHandle.SweepClosedLegacy) != 7 {
+			t.Error("data-handle sweep closed count was not loaded")
+		}
+		if wtValue(stats.Cache.EvictionPagesWalked, stats.Cache.EvictionPagesWalkedLegacy) != 11 {
+			t.Error("cache eviction walk count was not loaded")
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package collector
 
 import (
+	"strings"
+	"sync"
+	"time"
+
 	"github.com/golang/glog"
 	"github.com/percona/mongodb_exporter/collector/mongod"
 	"github.com/percona/mongodb_exporter/collector/mongos"
@@ -14,6 +18,12 @@ var (
 	Namespace = "mongodb"
 )
 
+const (
+	// configServerRetryInterval is the time to wait before dialing the config
+	// servers again after a failed connection
+	configServerRetryInterval = 1 * time.Minute
+)
+
 // MongodbCollectorOpts is the options of the mongodb collector.
 type MongodbCollectorOpts struct {
 	URI                   string
@@ -22,6 +32,15 @@ type MongodbCollectorOpts struct {
 	TLSPrivateKeyFile     string
 	TLSCaFile             string
 	TLSHostnameValidation bool
+	TCMallocVerbosity     int
+	TransactionsCurrentOp bool
+	OplogSamplerMaxDocs   int
+	OplogSamplerMaxTime   time.Duration
+	OplogSamplerMaxNs     int
+	ChunkSizesMaxTime     time.Duration
+	ChangelogWindow       time.Duration
+	ConnPoolMaxHosts      int
+	MongosStaleAfter      time.Duration
 }
 
 func (in MongodbCollectorOpts) toSessionOps() shared.MongoSessionOpts {
@@ -38,6 +57,12 @@ func (in MongodbCollectorOpts) toSessionOps() shared.MongoSessionOpts {
 // MongodbCollector is in charge of collecting mongodb's metrics.
 type MongodbCollector struct {
 	Opts MongodbCollectorOpts
+
+	// the session to the config servers of a mongos, kept across scrapes
+	configSession      *mgo.Session
+	configSessionHosts string
+	configSessionRetry time.Time
+	configSessionLock  sync.Mutex
 }
 
 // NewMongodbCollector returns a new instance of a MongodbCollector.
@@ -80,44 +105,177 @@ func (exporter *MongodbCollector) Collect(ch chan<- prometheus.Metric) {
 		glog.Infof("Connected to: %s (node type: %s, server version: %s)", shared.RedactMongoUri(exporter.Opts.URI), nodeType, serverVersion)
 		switch {
 		case nodeType == "mongos":
-			exporter.collectMongos(mongoSess, ch)
+			exporter.collectMongos(mongoSess, serverVersion, ch)
 		case nodeType == "mongod":
-			exporter.collectMongod(mongoSess, ch)
-		case nodeType == "replset":
-			exporter.collectMongodReplSet(mongoSess, ch)
+			exporter.collectMongod(mongoSess, serverVersion, ch)
+		case nodeType == "replset" || nodeType == "configsvr":
+			exporter.collectMongodReplSet(mongoSess, serverVersion, ch)
 		default:
 			glog.Infof("Unrecognized node type %s!", nodeType)
 		}
 	}
 }
 
-func (exporter *MongodbCollector) collectMongos(session *mgo.Session, ch chan<- prometheus.Metric) {
+func (exporter *MongodbCollector) collectMongos(session *mgo.Session, serverVersion string, ch chan<- prometheus.Metric) {
 	// read from primaries only when using mongos to avoid SERVER-27864
 	session.SetMode(mgo.Strong, true)
 
 	glog.Info("Collecting Server Status")
 	serverStatus := collector_mongos.GetServerStatus(session)
 	if serverStatus != nil {
+		if !shared.EnabledGroups["transactions"] {
+			serverStatus.LogicalSessionRecordCache = nil
+			serverStatus.Transactions = nil
+		} else if serverStatus.Transactions != nil && exporter.Opts.TransactionsCurrentOp {
+			oldestOpenSeconds, err := collector_mongos.GetOldestOpenTransactionSeconds(session)
+			if err == nil {
+				serverStatus.Transactions.OldestOpenSeconds = &oldestOpenSeconds
+			}
+		}
 		serverStatus.Export(ch)
 	}
 
+	if shared.EnabledGroups["config_servers"] {
+		configSess := exporter.configServerSession(session)
+		if configSess != nil {
+			defer configSess.Close()
+		}
+
+		glog.Info("Collecting Config Server Replset Status")
+		configServerStatus := collector_mongos.GetConfigServerReplSetStatus(configSess)
+		if configServerStatus != nil {
+			configServerStatus.Export(ch)
+		}
+	}
+
 	glog.Info("Collecting Sharding Status")
-	shardingStatus := collector_mongos.GetShardingStatus(session)
+	shardingStatus := collector_mongos.GetShardingStatus(session, collector_mongos.ShardingStatusOpts{
+		ServerVersion:            serverVersion,
+		ChangelogWindow:          exporter.Opts.ChangelogWindow,
+		MongosStaleAfter:         exporter.Opts.MongosStaleAfter,
+		BalancerCollectionStatus: shared.EnabledGroups["balancer_collection_status"],
+	})
 	if shardingStatus != nil {
 		shardingStatus.Export(ch)
 	}
+
+	if shared.EnabledGroups["connpool"] {
+		glog.Info("Collecting Connection Pool Stats")
+		connPoolStats := collector_mongos.GetConnPoolStats(session, exporter.Opts.ConnPoolMaxHosts)
+		if connPoolStats != nil {
+			connPoolStats.Export(ch)
+		}
+	}
+
+	// $currentOp reports the operations of the shards from mongos since version 4.0
+	if shared.EnabledGroups["admin_ops"] && shared.IsVersionGreater(serverVersion, 4, 0, 0) {
+		glog.Info("Collecting Admin Operations")
+		adminOps := collector_mongos.GetAdminOps(session)
+		if adminOps != nil {
+			adminOps.Export(ch)
+		}
+	}
+
+	if shared.EnabledGroups["unsharded_collections"] {
+		glog.Info("Collecting Sharding Unsharded Collections")
+		unshardedCollections := collector_mongos.GetShardingUnshardedCollectionStatus(session)
+		if unshardedCollections != nil {
+			unshardedCollections.Export(ch)
+		}
+	}
+
+	if shared.EnabledGroups["chunk_sizes"] {
+		glog.Info("Collecting Sharding Chunk Sizes")
+		chunkSizes := collector_mongos.GetShardingChunkSizes(session, collector_mongos.ShardingChunkSizesOpts{
+			MaxTime: exporter.Opts.ChunkSizesMaxTime,
+		})
+		if chunkSizes != nil {
+			chunkSizes.Export(ch)
+		}
+	}
+}
+
+// configServerSession returns a copy of the session to the config servers of
+// the cluster of a mongos, dialed with the credentials and TLS options of the
+// exporter. The session is kept across scrapes and dialed again when the config
+// servers change. After a failed dial the config servers are skipped for
+// configServerRetryInterval. The caller closes the returned session.
+func (exporter *MongodbCollector) configServerSession(session *mgo.Session) *mgo.Session {
+	_, hosts := collector_mongos.GetConfigServerHosts(session)
+	if len(hosts) == 0 {
+		return nil
+	}
+	key := strings.Join(hosts, ",")
+
+	exporter.configSessionLock.Lock()
+	defer exporter.configSessionLock.Unlock()
+
+	if exporter.configSession != nil && exporter.configSessionHosts != key {
+		exporter.configSession.Close()
+		exporter.configSession = nil
+	}
+	if exporter.configSession == nil {
+		if time.Now().Before(exporter.configSessionRetry) {
+			return nil
+		}
+		opts := exporter.Opts.toSessionOps()
+		opts.Hosts = hosts
+		exporter.configSession = shared.MongoSession(opts)
+		if exporter.configSession == nil {
+			exporter.configSessionRetry = time.Now().Add(configServerRetryInterval)
+			return nil
+		}
+		exporter.configSessionHosts = key
+	}
+	// drop the sockets of a previous failure, mgo resyncs with the servers
+	exporter.configSession.Refresh()
+	return exporter.configSession.Copy()
 }
 
-func (exporter *MongodbCollector) collectMongod(session *mgo.Session, ch chan<- prometheus.Metric) {
+func (exporter *MongodbCollector) collectMongod(session *mgo.Session, serverVersion string, ch chan<- prometheus.Metric) {
 	glog.Info("Collecting Server Status")
-	serverStatus := collector_mongod.GetServerStatus(session)
+	tcmallocVerbosity := 0
+	if shared.EnabledGroups["tcmalloc"] {
+		tcmallocVerbosity = exporter.Opts.TCMallocVerbosity
+	}
+	serverStatus := collector_mongod.GetServerStatus(session, tcmallocVerbosity)
 	if serverStatus != nil {
+		if !shared.EnabledGroups["sharding_statistics"] {
+			serverStatus.ShardingStatistics = nil
+		}
+		if !shared.EnabledGroups["transactions"] {
+			serverStatus.LogicalSessionRecordCache = nil
+			serverStatus.Transactions = nil
+		} else if serverStatus.Transactions != nil && exporter.Opts.TransactionsCurrentOp {
+			oldestOpenSeconds, err := collector_mongod.GetOldestOpenTransactionSeconds(session)
+			if err == nil {
+				serverStatus.Transactions.OldestOpenSeconds = &oldestOpenSeconds
+			}
+		}
 		serverStatus.Export(ch)
 	}
+
+	// mongod shards report their sharding connection pools in connPoolStats since version 3.6
+	if shared.EnabledGroups["connpool"] && shared.IsVersionGreater(serverVersion, 3, 6, 0) {
+		glog.Info("Collecting Connection Pool Stats")
+		connPoolStats := collector_mongod.GetConnPoolStats(session, exporter.Opts.ConnPoolMaxHosts)
+		if connPoolStats != nil {
+			connPoolStats.Export(ch)
+		}
+	}
+
+	// the $currentOp aggregation stage is available since version 3.6
+	if shared.EnabledGroups["admin_ops"] && shared.IsVersionGreater(serverVersion, 3, 6, 0) {
+		glog.Info("Collecting Admin Operations")
+		adminOps := collector_mongod.GetAdminOps(session)
+		if adminOps != nil {
+			adminOps.Export(ch)
+		}
+	}
 }
 
-func (exporter *MongodbCollector) collectMongodReplSet(session *mgo.Session, ch chan<- prometheus.Metric) {
-	exporter.collectMongod(session, ch)
+func (exporter *MongodbCollector) collectMongodReplSet(session *mgo.Session, serverVersion string, ch chan<- prometheus.Metric) {
+	exporter.collectMongod(session, serverVersion, ch)
 
 	glog.Info("Collecting Replset Status")
 	replSetStatus := collector_mongod.GetReplSetStatus(session)
@@ -125,9 +283,30 @@ func (exporter *MongodbCollector) collectMongodReplSet(session *mgo.Session, ch
 		replSetStatus.Export(ch)
 	}
 
+	if shared.EnabledGroups["replset_config"] {
+		glog.Info("Collecting Replset Config")
+		replSetConfig := collector_mongod.GetReplSetConfig(session)
+		if replSetConfig != nil {
+			replSetConfig.Status = replSetStatus
+			replSetConfig.Export(ch)
+		}
+	}
+
 	glog.Info("Collecting Replset Oplog Status")
 	oplogStatus := collector_mongod.GetOplogStatus(session)
 	if oplogStatus != nil {
 		oplogStatus.Export(ch)
 	}
+
+	if shared.EnabledGroups["oplog_sampler"] {
+		glog.Info("Collecting Oplog Sample")
+		oplogSample := collector_mongod.GetOplogSample(session, collector_mongod.OplogSamplerOpts{
+			MaxDocs:       exporter.Opts.OplogSamplerMaxDocs,
+			MaxTime:       exporter.Opts.OplogSamplerMaxTime,
+			MaxNamespaces: exporter.Opts.OplogSamplerMaxNs,
+		})
+		if oplogSample != nil {
+			oplogSample.Export(ch)
+		}
+	}
 }
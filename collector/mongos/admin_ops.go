@@ -0,0 +1,38 @@
+package collector_mongos
+
+import (
+	"github.com/golang/glog"
+	"github.com/percona/mongodb_exporter/shared"
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+var adminOpsMetrics = shared.NewAdminOpsMetrics(Namespace)
+
+// AdminOps keeps the running admin operations reported by currentOp
+type AdminOps struct {
+	Ops []shared.AdminOp
+}
+
+// Export exports the data to prometheus.
+func (ops *AdminOps) Export(ch chan<- prometheus.Metric) {
+	adminOpsMetrics.Export(ops.Ops, ch)
+}
+
+// Describe describes the metrics for prometheus
+func (ops *AdminOps) Describe(ch chan<- *prometheus.Desc) {
+	adminOpsMetrics.Describe(ch)
+}
+
+// GetAdminOps returns the index builds, compact, renameCollection and
+// reshardCollection operations running on the shards, using the $currentOp
+// aggregation stage with localOps disabled (4.0+)
+func GetAdminOps(session *mgo.Session) *AdminOps {
+	ops, err := shared.GetAdminOps(session, bson.M{"allUsers": true, "localOps": false})
+	if err != nil {
+		glog.Errorf("Failed to get admin operations from currentOp: %s", err)
+		return nil
+	}
+	return &AdminOps{Ops: ops}
+}
@@ -0,0 +1,56 @@
+package collector_mongos
+
+import (
+	"strings"
+
+	"github.com/golang/glog"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// parseConnectionString splits a "setName/host1,host2" shard or config server
+// connection string, the set name is empty for mirrored config servers (SCCC)
+func parseConnectionString(connectionString string) (string, []string) {
+	var setName string
+	if i := strings.Index(connectionString, "/"); i >= 0 {
+		setName = connectionString[:i]
+		connectionString = connectionString[i+1:]
+	}
+	var hosts []string
+	for _, host := range strings.Split(connectionString, ",") {
+		if host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return setName, hosts
+}
+
+// GetConfigServerConnectionString returns the connection string of the config
+// servers from getShardMap, or from serverStatus.sharding when getShardMap is
+// not available
+func GetConfigServerConnectionString(session *mgo.Session) string {
+	shardMap := struct {
+		Map map[string]string `bson:"map"`
+	}{}
+	err := session.DB("admin").Run(bson.D{{"getShardMap", 1}}, &shardMap)
+	if err == nil && shardMap.Map["config"] != "" {
+		return shardMap.Map["config"]
+	}
+
+	serverStatus := struct {
+		Sharding struct {
+			ConfigsvrConnectionString string `bson:"configsvrConnectionString"`
+		} `bson:"sharding"`
+	}{}
+	err = session.DB("admin").Run(bson.D{{"serverStatus", 1}, {"recordStats", 0}}, &serverStatus)
+	if err != nil {
+		glog.Errorf("Failed to get the config server connection string: %s", err)
+		return ""
+	}
+	return serverStatus.Sharding.ConfigsvrConnectionString
+}
+
+// GetConfigServerHosts returns the replica set name and hosts of the config servers
+func GetConfigServerHosts(session *mgo.Session) (string, []string) {
+	return parseConnectionString(GetConfigServerConnectionString(session))
+}
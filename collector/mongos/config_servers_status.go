@@ -0,0 +1,119 @@
+package collector_mongos
+
+import (
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/percona/mongodb_exporter/shared"
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	// configServerReplSetLabel is the rs label value of the config server replica set metrics
+	configServerReplSetLabel = "configsvr"
+)
+
+var (
+	configServerStatusOk = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "replset",
+		Name:      "status_ok",
+		Help:      "Boolean reporting if replSetGetStatus could be read from the replica set (1 = ok/0 = unreachable or failed)",
+	}, []string{"rs"})
+	configServerMemberHealth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "replset",
+		Name:      "member_health",
+		Help:      "Boolean reporting if the replica set member is up (1 = up/0 = down)",
+	}, []string{"rs", "set", "name"})
+	configServerMemberState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "replset",
+		Name:      "member_state",
+		Help:      "The state of a member of the replica set labelled by rs, with 1 for its current state and 0 for the other states",
+	}, []string{"rs", "set", "name", "state"})
+	configServerMemberReplicationLag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "replset",
+		Name:      "member_replication_lag_seconds",
+		Help:      "The replication lag of the member behind the primary in seconds",
+	}, []string{"rs", "set", "name"})
+)
+
+// ReplSetStatusMember represents an array element of ReplSetStatus.Members
+type ReplSetStatusMember struct {
+	Name       string    `bson:"name"`
+	Health     float64   `bson:"health"`
+	State      int32     `bson:"state"`
+	OptimeDate time.Time `bson:"optimeDate"`
+}
+
+// ReplSetStatus keeps the part of replSetGetStatus used to report the health
+// of the config server replica set from a mongos
+type ReplSetStatus struct {
+	Set     string                `bson:"set"`
+	Members []ReplSetStatusMember `bson:"members"`
+
+	// Ok is false when replSetGetStatus could not be read
+	Ok bool `bson:"-"`
+}
+
+// replicationLags returns the lag of every member with an optime, behind the
+// primary or the most recent optime when there is no primary
+func (status *ReplSetStatus) replicationLags() map[string]float64 {
+	optimes := make([]shared.ReplSetMemberOptime, 0, len(status.Members))
+	for _, member := range status.Members {
+		optimes = append(optimes, shared.ReplSetMemberOptime{Name: member.Name, State: member.State, OptimeDate: member.OptimeDate})
+	}
+	return shared.ReplSetReplicationLags(optimes)
+}
+
+// Export exports the data to prometheus.
+func (status *ReplSetStatus) Export(ch chan<- prometheus.Metric) {
+	configServerMemberHealth.Reset()
+	configServerMemberState.Reset()
+	configServerMemberReplicationLag.Reset()
+
+	configServerStatusOk.WithLabelValues(configServerReplSetLabel).Set(boolValue(status.Ok))
+	for _, member := range status.Members {
+		configServerMemberHealth.WithLabelValues(configServerReplSetLabel, status.Set, member.Name).Set(member.Health)
+		for state, stateStr := range shared.ReplSetMemberStates {
+			configServerMemberState.WithLabelValues(configServerReplSetLabel, status.Set, member.Name, stateStr).Set(boolValue(state == member.State))
+		}
+	}
+	for name, lag := range status.replicationLags() {
+		configServerMemberReplicationLag.WithLabelValues(configServerReplSetLabel, status.Set, name).Set(lag)
+	}
+
+	configServerStatusOk.Collect(ch)
+	configServerMemberHealth.Collect(ch)
+	configServerMemberState.Collect(ch)
+	configServerMemberReplicationLag.Collect(ch)
+}
+
+// Describe describes the metrics for prometheus
+func (status *ReplSetStatus) Describe(ch chan<- *prometheus.Desc) {
+	configServerStatusOk.Describe(ch)
+	configServerMemberHealth.Describe(ch)
+	configServerMemberState.Describe(ch)
+	configServerMemberReplicationLag.Describe(ch)
+}
+
+// GetConfigServerReplSetStatus returns the replSetGetStatus of the config
+// servers, session is a connection to the config servers and may be nil when
+// they are unreachable
+func GetConfigServerReplSetStatus(session *mgo.Session) *ReplSetStatus {
+	status := &ReplSetStatus{}
+	if session == nil {
+		return status
+	}
+	err := session.DB("admin").Run(bson.D{{"replSetGetStatus", 1}}, status)
+	if err != nil {
+		glog.Errorf("Failed to get the config server replSet status: %s", err)
+		return status
+	}
+	status.Ok = true
+	return status
+}
@@ -0,0 +1,47 @@
+package collector_mongos
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_ParseConnectionString(t *testing.T) {
+	setName, hosts := parseConnectionString("csrs/cfg1:27019,cfg2:27019,cfg3:27019")
+	if setName != "csrs" || len(hosts) != 3 || hosts[0] != "cfg1:27019" {
+		t.Errorf("Unexpected set name %s and hosts %v", setName, hosts)
+	}
+
+	setName, hosts = parseConnectionString("cfg1:27019,cfg2:27019")
+	if setName != "" || len(hosts) != 2 {
+		t.Errorf("Unexpected set name %s and hosts %v", setName, hosts)
+	}
+
+	if _, hosts = parseConnectionString(""); len(hosts) != 0 {
+		t.Errorf("Expected no hosts, got %v", hosts)
+	}
+}
+
+func Test_ConfigServerReplicationLags(t *testing.T) {
+	now := time.Now()
+	status := &ReplSetStatus{
+		Set: "csrs",
+		Members: []ReplSetStatusMember{
+			{Name: "cfg1:27019", State: 2, OptimeDate: now.Add(-3 * time.Second)},
+			{Name: "cfg2:27019", State: 1, OptimeDate: now},
+			{Name: "cfg3:27019", State: 8},
+		},
+	}
+	lags := status.replicationLags()
+	if len(lags) != 2 || lags["cfg1:27019"] != 3 || lags["cfg2:27019"] != 0 {
+		t.Errorf("Unexpected replication lags %v", lags)
+	}
+
+	// during an election the lag is computed against the most recent optime
+	status.Members[1].State = 2
+	status.Members[0].OptimeDate = now.Add(-4 * time.Second)
+	status.Members[1].OptimeDate = now.Add(-1 * time.Second)
+	lags = status.replicationLags()
+	if len(lags) != 2 || lags["cfg1:27019"] != 3 || lags["cfg2:27019"] != 0 {
+		t.Errorf("Unexpected replication lags without a primary %v", lags)
+	}
+}
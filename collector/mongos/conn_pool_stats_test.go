@@ -0,0 +1,29 @@
+package collector_mongos
+
+import (
+	"testing"
+)
+
+func Test_LimitConnPoolHosts(t *testing.T) {
+	hosts := map[string]ConnPoolHostStats{
+		"a:27017": {InUse: 1, Available: 2, Created: 3},
+		"b:27017": {InUse: 4, Available: 5, Created: 6},
+		"c:27017": {InUse: 7, Available: 8, Created: 9},
+	}
+
+	if limited := limitConnPoolHosts(hosts, 0); len(limited) != 3 {
+		t.Errorf("Expected all hosts without a limit, got %d", len(limited))
+	}
+
+	limited := limitConnPoolHosts(hosts, 1)
+	if len(limited) != 2 {
+		t.Fatalf("Expected 1 host and the other host, got %d", len(limited))
+	}
+	if limited["a:27017"].InUse != 1 {
+		t.Error("Expected the first host in sorted order to be kept")
+	}
+	other := limited[connPoolOtherHost]
+	if other.InUse != 11 || other.Available != 13 || other.Created != 15 {
+		t.Errorf("Unexpected other host counters %+v", other)
+	}
+}
@@ -49,6 +49,9 @@ type ServerStatus struct {
 	Metrics        *MetricsStats        `bson:"metrics"`
 
 	Cursors *Cursors `bson:"cursors"`
+
+	LogicalSessionRecordCache *LogicalSessionStats `bson:"logicalSessionRecordCache"`
+	Transactions              *TransactionStats    `bson:"transactions"`
 }
 
 // Export exports the server status to be consumed by prometheus.
@@ -84,6 +87,12 @@ func (status *ServerStatus) Export(ch chan<- prometheus.Metric) {
 	if status.Cursors != nil {
 		status.Cursors.Export(ch)
 	}
+	if status.LogicalSessionRecordCache != nil {
+		status.LogicalSessionRecordCache.Export(ch)
+	}
+	if status.Transactions != nil {
+		status.Transactions.Export(ch)
+	}
 }
 
 // Describe describes the server status for prometheus.
@@ -116,6 +125,12 @@ func (status *ServerStatus) Describe(ch chan<- *prometheus.Desc) {
 	if status.Cursors != nil {
 		status.Cursors.Describe(ch)
 	}
+	if status.LogicalSessionRecordCache != nil {
+		status.LogicalSessionRecordCache.Describe(ch)
+	}
+	if status.Transactions != nil {
+		status.Transactions.Describe(ch)
+	}
 }
 
 // GetServerStatus returns the server status info.
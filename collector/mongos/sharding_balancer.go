@@ -0,0 +1,277 @@
+package collector_mongos
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+var (
+	balancerMode = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding",
+		Name:      "balancer_mode",
+		Help:      "The mode of the balancer reported by balancerStatus as a stateset: 1 for the current mode, 0 for the other modes (3.4+)",
+	}, []string{"mode"})
+	balancerInRound = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding",
+		Name:      "balancer_in_round",
+		Help:      "Boolean reporting if the balancer is running a balancing round (1 = yes/0 = no) (3.4+)",
+	})
+	balancerRoundsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding",
+		Name:      "balancer_rounds_total",
+		Help:      "The total number of balancing rounds since the config server primary started, reported by balancerStatus (3.4+)",
+	})
+	balancerRoundDurationSeconds = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding",
+		Name:      "balancer_round_duration_seconds",
+		Help:      "The duration of the balancing rounds logged as balancer.round in config.actionlog",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 4, 10),
+	})
+	balancerRoundChunksTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding",
+		Name:      "balancer_round_chunks_total",
+		Help:      "The total number of candidate and moved chunks of the balancing rounds logged in config.actionlog",
+	}, []string{"type"})
+	balancerRoundErrorsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding",
+		Name:      "balancer_round_errors_total",
+		Help:      "The total number of balancing rounds logged with an error in config.actionlog",
+	})
+	balancerActiveWindowSeconds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding",
+		Name:      "balancer_active_window_seconds",
+		Help:      "The start and stop of the balancer active window in seconds since midnight, in the time zone of the config servers",
+	}, []string{"bound"})
+)
+
+var (
+	// the time and _id of the last processed config.actionlog document, kept across scrapes
+	actionlogLastTime time.Time
+	actionlogLastId   interface{}
+	actionlogStarted  bool
+	actionlogLock     sync.Mutex
+)
+
+// balancerModes are the balancer modes reported by balancerStatus
+var balancerModes = []string{"full", "autoSplitOnly", "off"}
+
+// BalancerStatus keeps the data returned by the balancerStatus command (3.4+)
+type BalancerStatus struct {
+	Mode              string  `bson:"mode"`
+	InBalancerRound   bool    `bson:"inBalancerRound"`
+	NumBalancerRounds float64 `bson:"numBalancerRounds"`
+}
+
+// BalancerActiveWindow is the activeWindow of the balancer settings, start and
+// stop are "HH:MM" strings
+type BalancerActiveWindow struct {
+	Start string `bson:"start"`
+	Stop  string `bson:"stop"`
+}
+
+// BalancerSettings represents the balancer document of config.settings
+type BalancerSettings struct {
+	Stopped      bool                  `bson:"stopped"`
+	Mode         string                `bson:"mode"`
+	ActiveWindow *BalancerActiveWindow `bson:"activeWindow,omitempty"`
+}
+
+// BalancerRound represents a balancer.round document of config.actionlog
+type BalancerRound struct {
+	Id      interface{} `bson:"_id"`
+	Time    time.Time   `bson:"time"`
+	Details struct {
+		ExecutionTimeMillis float64 `bson:"executionTimeMillis"`
+		ErrorOccured        bool    `bson:"errorOccured"`
+		ErrorOccurred       bool    `bson:"errorOccurred"`
+		CandidateChunks     float64 `bson:"candidateChunks"`
+		ChunksMoved         float64 `bson:"chunksMoved"`
+	} `bson:"details"`
+}
+
+// ShardingBalancerStats keeps the balancer state, settings and rounds
+type ShardingBalancerStats struct {
+	Status   *BalancerStatus
+	Settings *BalancerSettings
+	// Rounds are the balancing rounds logged since the previous scrape
+	Rounds []BalancerRound
+}
+
+// activeWindowSeconds parses a "HH:MM" active window bound to seconds since midnight
+func activeWindowSeconds(bound string) (float64, bool) {
+	split := strings.SplitN(bound, ":", 2)
+	if len(split) != 2 {
+		return 0, false
+	}
+	hours, err := strconv.Atoi(split[0])
+	if err != nil {
+		return 0, false
+	}
+	minutes, err := strconv.Atoi(split[1])
+	if err != nil {
+		return 0, false
+	}
+	return float64(hours*3600 + minutes*60), true
+}
+
+// IsEnabled returns 1 if the balancer is enabled, 0 otherwise. The mode of
+// balancerStatus is preferred over the stopped flag of the settings.
+func (status *ShardingBalancerStats) IsEnabled() float64 {
+	if status.Status != nil {
+		if status.Status.Mode == "off" {
+			return 0
+		}
+		return 1
+	}
+	if status.Settings != nil && (status.Settings.Stopped || status.Settings.Mode == "off") {
+		return 0
+	}
+	return 1
+}
+
+// Export exports the data to prometheus.
+func (status *ShardingBalancerStats) Export(ch chan<- prometheus.Metric) {
+	if status.Status != nil {
+		for _, mode := range balancerModes {
+			var value float64
+			if mode == status.Status.Mode {
+				value = 1
+			}
+			balancerMode.WithLabelValues(mode).Set(value)
+		}
+		var inRound float64
+		if status.Status.InBalancerRound {
+			inRound = 1
+		}
+		balancerInRound.Set(inRound)
+		balancerRoundsTotal.Set(status.Status.NumBalancerRounds)
+
+		balancerMode.Collect(ch)
+		balancerInRound.Collect(ch)
+		balancerRoundsTotal.Collect(ch)
+	}
+
+	balancerActiveWindowSeconds.Reset()
+	if status.Settings != nil && status.Settings.ActiveWindow != nil {
+		if start, ok := activeWindowSeconds(status.Settings.ActiveWindow.Start); ok {
+			balancerActiveWindowSeconds.WithLabelValues("start").Set(start)
+		}
+		if stop, ok := activeWindowSeconds(status.Settings.ActiveWindow.Stop); ok {
+			balancerActiveWindowSeconds.WithLabelValues("stop").Set(stop)
+		}
+	}
+
+	for _, round := range status.Rounds {
+		balancerRoundDurationSeconds.Observe(round.Details.ExecutionTimeMillis / 1000)
+		balancerRoundChunksTotal.WithLabelValues("candidate").Add(round.Details.CandidateChunks)
+		balancerRoundChunksTotal.WithLabelValues("moved").Add(round.Details.ChunksMoved)
+		if round.Details.ErrorOccured || round.Details.ErrorOccurred {
+			balancerRoundErrorsTotal.Inc()
+		}
+	}
+
+	balancerActiveWindowSeconds.Collect(ch)
+	balancerRoundDurationSeconds.Collect(ch)
+	balancerRoundChunksTotal.Collect(ch)
+	balancerRoundErrorsTotal.Collect(ch)
+}
+
+// Describe describes the metrics for prometheus
+func (status *ShardingBalancerStats) Describe(ch chan<- *prometheus.Desc) {
+	balancerMode.Describe(ch)
+	balancerInRound.Describe(ch)
+	balancerRoundsTotal.Describe(ch)
+	balancerActiveWindowSeconds.Describe(ch)
+	balancerRoundDurationSeconds.Describe(ch)
+	balancerRoundChunksTotal.Describe(ch)
+	balancerRoundErrorsTotal.Describe(ch)
+}
+
+// GetBalancerStatus returns the output of balancerStatus, nil before version 3.4
+func GetBalancerStatus(session *mgo.Session) *BalancerStatus {
+	status := &BalancerStatus{}
+	err := session.DB("admin").Run(bson.D{{"balancerStatus", 1}}, status)
+	if err != nil {
+		glog.Infof("Failed to get balancerStatus, falling back to config.settings: %s", err)
+		return nil
+	}
+	return status
+}
+
+// GetBalancerSettings returns the balancer document of config.settings
+func GetBalancerSettings(session *mgo.Session) *BalancerSettings {
+	settings := &BalancerSettings{}
+	err := session.DB("config").C("settings").Find(bson.M{"_id": "balancer"}).One(settings)
+	if err != nil {
+		if err != mgo.ErrNotFound {
+			glog.Error("Failed to execute find query on 'config.settings'!")
+		}
+		return nil
+	}
+	return settings
+}
+
+// GetBalancerRounds returns the balancer.round documents of config.actionlog
+// written since the last call, the first call only records the newest document
+func GetBalancerRounds(session *mgo.Session) []BalancerRound {
+	actionlogLock.Lock()
+	defer actionlogLock.Unlock()
+
+	rounds := []BalancerRound{}
+	coll := session.DB("config").C("actionlog")
+	if !actionlogStarted {
+		var last BalancerRound
+		err := coll.Find(bson.M{"what": "balancer.round"}).Sort("-time", "-_id").One(&last)
+		if err != nil && err != mgo.ErrNotFound {
+			glog.Error("Failed to execute find query on 'config.actionlog'!")
+			return rounds
+		}
+		// with an empty actionlog the zero time makes the next query return every round
+		actionlogLastTime = last.Time
+		actionlogLastId = last.Id
+		actionlogStarted = true
+		return rounds
+	}
+
+	query := bson.M{"what": "balancer.round", "time": bson.M{"$gt": actionlogLastTime}}
+	if actionlogLastId != nil {
+		query = bson.M{"what": "balancer.round", "$or": []bson.M{
+			{"time": bson.M{"$gt": actionlogLastTime}},
+			{"time": actionlogLastTime, "_id": bson.M{"$gt": actionlogLastId}},
+		}}
+	}
+	err := coll.Find(query).Sort("time", "_id").All(&rounds)
+	if err != nil {
+		glog.Error("Failed to execute find query on 'config.actionlog'!")
+		return rounds
+	}
+	if len(rounds) > 0 {
+		actionlogLastTime = rounds[len(rounds)-1].Time
+		actionlogLastId = rounds[len(rounds)-1].Id
+	}
+	return rounds
+}
+
+// GetShardingBalancerStatus returns the balancer state, settings and the
+// balancing rounds logged since the previous scrape
+func GetShardingBalancerStatus(session *mgo.Session) *ShardingBalancerStats {
+	return &ShardingBalancerStats{
+		Status:   GetBalancerStatus(session),
+		Settings: GetBalancerSettings(session),
+		Rounds:   GetBalancerRounds(session),
+	}
+}
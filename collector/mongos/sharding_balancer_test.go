@@ -0,0 +1,39 @@
+package collector_mongos
+
+import (
+	"testing"
+)
+
+func Test_ActiveWindowSeconds(t *testing.T) {
+	if seconds, ok := activeWindowSeconds("23:30"); !ok || seconds != 84600 {
+		t.Errorf("Expected 84600 seconds for 23:30, got %v", seconds)
+	}
+	if seconds, ok := activeWindowSeconds("6:00"); !ok || seconds != 21600 {
+		t.Errorf("Expected 21600 seconds for 6:00, got %v", seconds)
+	}
+	if _, ok := activeWindowSeconds("6am"); ok {
+		t.Error("Expected 6am to be rejected")
+	}
+}
+
+func Test_BalancerIsEnabled(t *testing.T) {
+	status := &ShardingBalancerStats{
+		Status:   &BalancerStatus{Mode: "full"},
+		Settings: &BalancerSettings{Stopped: true},
+	}
+	if status.IsEnabled() != 1 {
+		t.Error("Expected the balancerStatus mode to be preferred over the settings")
+	}
+	status.Status.Mode = "off"
+	if status.IsEnabled() != 0 {
+		t.Error("Expected the balancer to be disabled in mode off")
+	}
+	status.Status = nil
+	if status.IsEnabled() != 0 {
+		t.Error("Expected the balancer to be disabled when stopped in the settings")
+	}
+	status.Settings = nil
+	if status.IsEnabled() != 1 {
+		t.Error("Expected the balancer to be enabled without settings")
+	}
+}
@@ -1,6 +1,7 @@
 package collector_mongos
 
 import (
+	"sync"
 	"time"
 	"github.com/golang/glog"
 	"github.com/prometheus/client_golang/prometheus"
@@ -13,8 +14,24 @@ var (
 		Namespace:	Namespace,
 		Subsystem:	"sharding",
 		Name:		"changelog_10min_total",
-		Help:		"Total # of Cluster Balancer log events over the last 10 minutes",
+		Help:		"Total # of Cluster Balancer log events over the configured changelog window (10 minutes by default), prefer changelog_events_total",
 	}, []string{"event"})
+	shardingChangelogEventsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:	Namespace,
+		Subsystem:	"sharding",
+		Name:		"changelog_events_total",
+		Help:		"The total number of Cluster Balancer log events in config.changelog since the exporter started",
+	}, []string{"event", "note"})
+)
+
+var (
+	// the time and _id of the last processed config.changelog document, kept across scrapes
+	changelogLastTime	time.Time
+	changelogLastId		string
+	changelogStarted	bool
+	changelogLock		sync.Mutex
+	// all event types seen since the exporter started, to report them as 0 in the window
+	changelogKnownEvents	= make(map[string]bool)
 )
 
 type ShardingChangelogSummaryId struct {
@@ -27,58 +44,124 @@ type ShardingChangelogSummary struct {
 	Count 	float64				`bson:"count"`
 }
 
+type ShardingChangelogEntryDetails struct {
+	Note	string			`bson:"note"`
+	Errmsg	string			`bson:"errmsg"`
+	// Other keeps the remaining details, like the "step N of M" timings of the moveChunk events
+	Other	map[string]interface{}	`bson:",inline"`
+}
+
+type ShardingChangelogEntry struct {
+	Id	string				`bson:"_id"`
+	Time	time.Time			`bson:"time"`
+	What	string				`bson:"what"`
+	Ns	string				`bson:"ns"`
+	Details	ShardingChangelogEntryDetails	`bson:"details"`
+}
+
 type ShardingChangelogStats struct {
 	Items	*[]ShardingChangelogSummary
+	// Entries are the config.changelog documents written since the previous scrape
+	Entries	[]ShardingChangelogEntry
+}
+
+// windowEvent returns the event label of the changelog_10min_total metric,
+// failed chunk migrations are reported as separate events
+func windowEvent(event string, note string) string {
+	switch event {
+		case "moveChunk.to", "moveChunk.from":
+			if note != "success" && note != "" {
+				return event + "_failed"
+			}
+	}
+	return event
 }
 
 func (status *ShardingChangelogStats) Export(ch chan<- prometheus.Metric) {
-	// set all expected event types to zero first, so they show in results if there was no events in the current time period
-	shardingChangelogInfo.WithLabelValues("moveChunk.start").Set(0)
-	shardingChangelogInfo.WithLabelValues("moveChunk.to").Set(0)
-	shardingChangelogInfo.WithLabelValues("moveChunk.to_failed").Set(0)
-	shardingChangelogInfo.WithLabelValues("moveChunk.from").Set(0)
-	shardingChangelogInfo.WithLabelValues("moveChunk.from_failed").Set(0)
-	shardingChangelogInfo.WithLabelValues("moveChunk.commit").Set(0)
-	shardingChangelogInfo.WithLabelValues("addShard").Set(0)
-	shardingChangelogInfo.WithLabelValues("removeShard.start").Set(0)
-	shardingChangelogInfo.WithLabelValues("shardCollection").Set(0)
-	shardingChangelogInfo.WithLabelValues("shardCollection.start").Set(0)
-	shardingChangelogInfo.WithLabelValues("split").Set(0)
-	shardingChangelogInfo.WithLabelValues("multi-split").Set(0)
+	changelogLock.Lock()
+	defer changelogLock.Unlock()
+
+	for _, entry := range status.Entries {
+		shardingChangelogEventsTotal.WithLabelValues(entry.What, entry.Details.Note).Inc()
+		changelogKnownEvents[windowEvent(entry.What, entry.Details.Note)] = true
+		observeChunkMigration(&entry)
+	}
+
+	// set all known event types to zero first, so they show in results if there was no events in the current time period
+	shardingChangelogInfo.Reset()
+	if status.Items != nil {
+		for _, item := range *status.Items {
+			changelogKnownEvents[windowEvent(item.Id.Event, item.Id.Note)] = true
+		}
+	}
+	for event := range changelogKnownEvents {
+		shardingChangelogInfo.WithLabelValues(event).Set(0)
+	}
 
 	// set counts for events found in our query
-	for _, item := range *status.Items {
-		event := item.Id.Event
-		note  := item.Id.Note
-		count := item.Count
-		switch event {
-			case "moveChunk.to":
-				if note == "success" || note == "" {
-					shardingChangelogInfo.WithLabelValues(event).Set(count)
-				} else {
-					shardingChangelogInfo.WithLabelValues(event + "_failed").Set(count)
-				}
-			case "moveChunk.from":
-				if note == "success" || note == "" {
-					shardingChangelogInfo.WithLabelValues(event).Set(count)
-				} else {
-					shardingChangelogInfo.WithLabelValues(event + "_failed").Set(count)
-				}
-			default:
-				shardingChangelogInfo.WithLabelValues(event).Set(count)
+	if status.Items != nil {
+		for _, item := range *status.Items {
+			event := windowEvent(item.Id.Event, item.Id.Note)
+			shardingChangelogInfo.WithLabelValues(event).Add(item.Count)
 		}
 	}
 	shardingChangelogInfo.Collect(ch)
+	shardingChangelogEventsTotal.Collect(ch)
+	chunkMigrationStepSeconds.Collect(ch)
+	chunkMigrationDurationSeconds.Collect(ch)
+	chunkMigrationFailuresTotal.Collect(ch)
 }
 
 func (status *ShardingChangelogStats) Describe(ch chan<- *prometheus.Desc) {
 	shardingChangelogInfo.Describe(ch)
+	shardingChangelogEventsTotal.Describe(ch)
+	chunkMigrationStepSeconds.Describe(ch)
+	chunkMigrationDurationSeconds.Describe(ch)
+	chunkMigrationFailuresTotal.Describe(ch)
+}
+
+// GetShardingChangelogEntries returns the config.changelog documents written
+// since the last call, the first call only records the newest document
+func GetShardingChangelogEntries(session *mgo.Session) []ShardingChangelogEntry {
+	changelogLock.Lock()
+	defer changelogLock.Unlock()
+
+	entries := []ShardingChangelogEntry{}
+	coll  := session.DB("config").C("changelog")
+	if !changelogStarted {
+		var last ShardingChangelogEntry
+		err := coll.Find(bson.M{}).Sort("-time", "-_id").One(&last)
+		if err != nil && err != mgo.ErrNotFound {
+			glog.Error("Failed to execute find query on 'config.changelog'!")
+			return entries
+		}
+		// with an empty changelog the zero time makes the next query return every event
+		changelogLastTime = last.Time
+		changelogLastId = last.Id
+		changelogStarted = true
+		return entries
+	}
+
+	query := bson.M{ "$or" : []bson.M{
+		{ "time" : bson.M{ "$gt" : changelogLastTime } },
+		{ "time" : changelogLastTime, "_id" : bson.M{ "$gt" : changelogLastId } },
+	} }
+	err := coll.Find(query).Select(bson.M{ "time" : 1, "what" : 1, "ns" : 1, "details" : 1 }).Sort("time", "_id").All(&entries)
+	if err != nil {
+		glog.Error("Failed to execute find query on 'config.changelog'!")
+		return entries
+	}
+	if len(entries) > 0 {
+		changelogLastTime = entries[len(entries)-1].Time
+		changelogLastId = entries[len(entries)-1].Id
+	}
+	return entries
 }
 
-func GetShardingChangelogStatus(session *mgo.Session) *ShardingChangelogStats {
+func GetShardingChangelogStatus(session *mgo.Session, window time.Duration) *ShardingChangelogStats {
 	var qresults []ShardingChangelogSummary
 	coll  := session.DB("config").C("changelog")
-	match := bson.M{ "time" : bson.M{ "$gt" : time.Now().Add(-10 * time.Minute) } }
+	match := bson.M{ "time" : bson.M{ "$gt" : time.Now().Add(-window) } }
 	group := bson.M{ "_id" : bson.M{ "event" : "$what", "note" : "$details.note" }, "count" : bson.M{ "$sum" : 1 } }
 
 	err := coll.Pipe([]bson.M{ { "$match" : match }, { "$group" : group } }).All(&qresults)
@@ -88,5 +171,6 @@ func GetShardingChangelogStatus(session *mgo.Session) *ShardingChangelogStats {
 
 	results := &ShardingChangelogStats{}
 	results.Items = &qresults
+	results.Entries = GetShardingChangelogEntries(session)
 	return results
 }
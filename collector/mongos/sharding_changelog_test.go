@@ -0,0 +1,23 @@
+package collector_mongos
+
+import (
+	"testing"
+)
+
+func Test_WindowEvent(t *testing.T) {
+	for _, test := range []struct {
+		event    string
+		note     string
+		expected string
+	}{
+		{"moveChunk.from", "success", "moveChunk.from"},
+		{"moveChunk.from", "aborted", "moveChunk.from_failed"},
+		{"moveChunk.to", "", "moveChunk.to"},
+		{"split", "aborted", "split"},
+		{"dropCollection.start", "", "dropCollection.start"},
+	} {
+		if event := windowEvent(test.event, test.note); event != test.expected {
+			t.Errorf("Expected %s for %s/%s, got %s", test.expected, test.event, test.note, event)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package collector_mongos
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+const (
+	// defaultChunkSizeMB is the chunk size used when config.settings has no chunksize document
+	defaultChunkSizeMB = 64
+)
+
+var (
+	shardingChunkSizeBytes = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "sharding", "chunk_size_bytes"),
+		"The size distribution of the chunks of a sharded collection, estimated with dataSize over the chunk ranges",
+		[]string{"ns"}, nil,
+	)
+	shardingChunkSizeBuckets = prometheus.ExponentialBuckets(1024*1024, 2, 11)
+
+	shardingChunkSizeSettingBytes = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding",
+		Name:      "chunk_size_setting_bytes",
+		Help:      "The maximum chunk size configured in config.settings, chunks above it are split or flagged as jumbo",
+	})
+	shardingCollectionOversizedChunks = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding",
+		Name:      "collection_oversized_chunks",
+		Help:      "The number of chunks of a sharded collection with an estimated size above the configured chunk size",
+	}, []string{"ns"})
+	shardingChunkSizesMeasured = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding",
+		Name:      "chunk_sizes_measured",
+		Help:      "The number of chunks measured with dataSize on the last scrape",
+	})
+)
+
+var (
+	// the estimated chunk sizes, kept across scrapes so the time budget only
+	// limits how fast they are refreshed
+	chunkSizes     = newChunkSizesState()
+	chunkSizesLock sync.Mutex
+)
+
+type chunkSize struct {
+	Ns    string
+	Bytes float64
+}
+
+// chunkSizesState keeps the estimated chunk sizes by chunk _id, the chunks
+// visited by the current pass over config.chunks and the _id of the last one
+type chunkSizesState struct {
+	Sizes map[string]chunkSize
+	Seen  map[string]bool
+	Last  interface{}
+}
+
+func newChunkSizesState() *chunkSizesState {
+	return &chunkSizesState{
+		Sizes: make(map[string]chunkSize),
+		Seen:  make(map[string]bool),
+	}
+}
+
+// visit records that the pass reached a chunk, the next scrape resumes after it
+func (state *chunkSizesState) visit(id interface{}) {
+	state.Last = id
+	state.Seen[fmt.Sprint(id)] = true
+}
+
+// record stores the estimated size of a visited chunk
+func (state *chunkSizesState) record(id interface{}, size chunkSize) {
+	state.Sizes[fmt.Sprint(id)] = size
+}
+
+// finishPass forgets the chunks that were not visited by a complete pass,
+// they were merged, split or belong to a dropped collection, and starts the
+// next pass from the first chunk. An incomplete pass resumes on the next scrape.
+func (state *chunkSizesState) finishPass(complete bool) {
+	if !complete {
+		return
+	}
+	for id := range state.Sizes {
+		if !state.Seen[id] {
+			delete(state.Sizes, id)
+		}
+	}
+	state.Seen = make(map[string]bool)
+	state.Last = nil
+}
+
+// sizesByNs returns the estimated chunk sizes per namespace
+func (state *chunkSizesState) sizesByNs() map[string][]float64 {
+	sizes := make(map[string][]float64)
+	for _, size := range state.Sizes {
+		sizes[size.Ns] = append(sizes[size.Ns], size.Bytes)
+	}
+	return sizes
+}
+
+// chunkSizeHistogram returns the cumulative bucket counts and the sum of the
+// chunk sizes of a collection, and the number of chunks above chunkSizeBytes
+func chunkSizeHistogram(sizes []float64, chunkSizeBytes float64) (map[float64]uint64, float64, float64) {
+	buckets := make(map[float64]uint64, len(shardingChunkSizeBuckets))
+	for _, bound := range shardingChunkSizeBuckets {
+		buckets[bound] = 0
+	}
+	var sum, oversized float64
+	for _, size := range sizes {
+		sum += size
+		if size > chunkSizeBytes {
+			oversized++
+		}
+		for _, bound := range shardingChunkSizeBuckets {
+			if size <= bound {
+				buckets[bound]++
+			}
+		}
+	}
+	return buckets, sum, oversized
+}
+
+type shardingChunk struct {
+	Id   interface{}  `bson:"_id"`
+	Ns   string       `bson:"ns"`
+	UUID *bson.Binary `bson:"uuid,omitempty"`
+	Min  bson.Raw     `bson:"min"`
+	Max  bson.Raw     `bson:"max"`
+}
+
+// ShardingChunkSizesOpts limits the work done to estimate the chunk sizes on each scrape
+type ShardingChunkSizesOpts struct {
+	MaxTime time.Duration
+}
+
+// ShardingChunkSizes keeps the estimated size of the chunks per namespace
+type ShardingChunkSizes struct {
+	ChunkSizeBytes float64
+	Sizes          map[string][]float64
+	Measured       float64
+}
+
+// Export exports the data to prometheus.
+func (status *ShardingChunkSizes) Export(ch chan<- prometheus.Metric) {
+	shardingCollectionOversizedChunks.Reset()
+
+	for ns, sizes := range status.Sizes {
+		buckets, sum, oversized := chunkSizeHistogram(sizes, status.ChunkSizeBytes)
+		ch <- prometheus.MustNewConstHistogram(shardingChunkSizeBytes, uint64(len(sizes)), sum, buckets, ns)
+		shardingCollectionOversizedChunks.WithLabelValues(ns).Set(oversized)
+	}
+	shardingChunkSizeSettingBytes.Set(status.ChunkSizeBytes)
+	shardingChunkSizesMeasured.Set(status.Measured)
+
+	shardingChunkSizeSettingBytes.Collect(ch)
+	shardingCollectionOversizedChunks.Collect(ch)
+	shardingChunkSizesMeasured.Collect(ch)
+}
+
+// Describe describes the metrics for prometheus
+func (status *ShardingChunkSizes) Describe(ch chan<- *prometheus.Desc) {
+	ch <- shardingChunkSizeBytes
+	shardingChunkSizeSettingBytes.Describe(ch)
+	shardingCollectionOversizedChunks.Describe(ch)
+	shardingChunkSizesMeasured.Describe(ch)
+}
+
+// GetChunkSizeSetting returns the maximum chunk size in bytes from config.settings
+func GetChunkSizeSetting(session *mgo.Session) float64 {
+	setting := struct {
+		Value float64 `bson:"value"`
+	}{}
+	err := session.DB("config").C("settings").Find(bson.M{"_id": "chunksize"}).One(&setting)
+	if err != nil || setting.Value <= 0 {
+		return defaultChunkSizeMB * 1024 * 1024
+	}
+	return setting.Value * 1024 * 1024
+}
+
+// getChunkDataSize runs dataSize over the range of a chunk, the command is
+// interrupted by the server after maxTime
+func getChunkDataSize(session *mgo.Session, ns string, keyPattern bson.Raw, chunk *shardingChunk, maxTime time.Duration) (float64, error) {
+	result := struct {
+		Size float64 `bson:"size"`
+	}{}
+	cmd := bson.D{
+		{"dataSize", ns},
+		{"keyPattern", keyPattern},
+		{"min", chunk.Min},
+		{"max", chunk.Max},
+		{"estimate", true},
+		{"maxTimeMS", chunkDataSizeMaxTimeMS(maxTime)},
+	}
+	db := strings.SplitN(ns, ".", 2)[0]
+	err := session.DB(db).Run(cmd, &result)
+	return result.Size, err
+}
+
+// chunkDataSizeMaxTimeMS returns the maxTimeMS of a dataSize command from the
+// time left in the budget, at least 1 as 0 means no limit to the server
+func chunkDataSizeMaxTimeMS(maxTime time.Duration) int64 {
+	if ms := int64(maxTime / time.Millisecond); ms > 0 {
+		return ms
+	}
+	return 1
+}
+
+// GetShardingChunkSizes estimates the size of the chunks with dataSize, resuming
+// from the last chunk measured on the previous scrape until the time budget is
+// exhausted. Chunks that were removed are forgotten once every chunk was visited.
+func GetShardingChunkSizes(session *mgo.Session, opts ShardingChunkSizesOpts) *ShardingChunkSizes {
+	chunkSizesLock.Lock()
+	defer chunkSizesLock.Unlock()
+
+	collections := GetShardedCollections(session)
+	nsByUUID := collectionNsByUUID(collections)
+	keyPatterns := make(map[string]bson.Raw)
+	for _, collection := range collections {
+		keyPatterns[collection.Ns] = collection.Key
+	}
+
+	results := &ShardingChunkSizes{
+		ChunkSizeBytes: GetChunkSizeSetting(session),
+	}
+
+	deadline := time.Now().Add(opts.MaxTime)
+	query := bson.M{}
+	if chunkSizes.Last != nil {
+		query["_id"] = bson.M{"$gt": chunkSizes.Last}
+	}
+	iter := session.DB("config").C("chunks").Find(query).Sort("_id").Iter()
+	complete := true
+	for {
+		chunk := shardingChunk{}
+		if !iter.Next(&chunk) {
+			break
+		}
+		if time.Now().After(deadline) {
+			complete = false
+			break
+		}
+		// a chunk that fails dataSize keeps its previous estimate
+		chunkSizes.visit(chunk.Id)
+
+		ns := chunk.Ns
+		if ns == "" && chunk.UUID != nil {
+			ns = nsByUUID[string(chunk.UUID.Data)]
+		}
+		keyPattern, ok := keyPatterns[ns]
+		if !ok {
+			continue
+		}
+		size, err := getChunkDataSize(session, ns, keyPattern, &chunk, deadline.Sub(time.Now()))
+		if err != nil {
+			glog.Errorf("Failed to get the data size of a chunk of %s: %s", ns, err)
+			continue
+		}
+		chunkSizes.record(chunk.Id, chunkSize{Ns: ns, Bytes: size})
+		results.Measured++
+	}
+	if err := iter.Close(); err != nil {
+		glog.Errorf("Failed to execute find query on 'config.chunks': %s", err)
+		complete = false
+	}
+
+	chunkSizes.finishPass(complete)
+	results.Sizes = chunkSizes.sizesByNs()
+
+	return results
+}
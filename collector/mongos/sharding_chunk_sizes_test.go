@@ -0,0 +1,85 @@
+package collector_mongos
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func Test_ChunkSizesStateResume(t *testing.T) {
+	state := newChunkSizesState()
+	first, second := bson.ObjectIdHex("5d0000000000000000000001"), bson.ObjectIdHex("5d0000000000000000000002")
+
+	state.visit(first)
+	state.record(first, chunkSize{Ns: "test.a", Bytes: 10})
+	state.finishPass(false)
+	if state.Last != first || len(state.Sizes) != 1 {
+		t.Errorf("an incomplete pass should resume after the last chunk, got %+v", state)
+	}
+
+	// a chunk that failed dataSize keeps the estimate of the previous pass
+	state.visit(second)
+	state.finishPass(true)
+	state.visit(first)
+	state.finishPass(true)
+	if state.Last != nil || len(state.Seen) != 0 {
+		t.Errorf("a complete pass should start over, got %+v", state)
+	}
+	if size, ok := state.Sizes[first.String()]; !ok || size.Bytes != 10 {
+		t.Errorf("a visited chunk lost its size, got %+v", state.Sizes)
+	}
+}
+
+func Test_ChunkSizesStatePrune(t *testing.T) {
+	state := newChunkSizesState()
+	state.visit("a")
+	state.record("a", chunkSize{Ns: "test.a", Bytes: 10})
+	state.visit("b")
+	state.record("b", chunkSize{Ns: "test.b", Bytes: 20})
+	state.finishPass(true)
+
+	// chunk b was merged away, it is only forgotten when the pass is complete
+	state.visit("a")
+	state.finishPass(false)
+	if len(state.Sizes) != 2 {
+		t.Errorf("an incomplete pass should not prune chunks, got %+v", state.Sizes)
+	}
+	state.finishPass(true)
+	if _, ok := state.Sizes["b"]; ok || len(state.Sizes) != 1 {
+		t.Errorf("an unseen chunk was not pruned, got %+v", state.Sizes)
+	}
+
+	sizes := state.sizesByNs()
+	if len(sizes) != 1 || len(sizes["test.a"]) != 1 || sizes["test.a"][0] != 10 {
+		t.Errorf("unexpected sizes per namespace %v", sizes)
+	}
+}
+
+func Test_ChunkSizeHistogram(t *testing.T) {
+	mb := float64(1024 * 1024)
+	buckets, sum, oversized := chunkSizeHistogram([]float64{0.5 * mb, 3 * mb, 80 * mb}, 64*mb)
+	if sum != 83.5*mb || oversized != 1 {
+		t.Errorf("unexpected sum %v and oversized chunks %v", sum, oversized)
+	}
+	for bound, count := range map[float64]uint64{1 * mb: 1, 2 * mb: 1, 4 * mb: 2, 64 * mb: 2, 128 * mb: 3, 1024 * mb: 3} {
+		if buckets[bound] != count {
+			t.Errorf("expected a cumulative count of %d for the %v bucket, got %d", count, bound, buckets[bound])
+		}
+	}
+	if len(buckets) != len(shardingChunkSizeBuckets) {
+		t.Errorf("expected every bucket to be set, got %v", buckets)
+	}
+}
+
+func Test_ChunkDataSizeMaxTimeMS(t *testing.T) {
+	if ms := chunkDataSizeMaxTimeMS(1500 * time.Millisecond); ms != 1500 {
+		t.Errorf("Expected a maxTimeMS of 1500, got %d", ms)
+	}
+	// 0 disables maxTimeMS on the server, an exhausted budget is sent as 1ms
+	for _, maxTime := range []time.Duration{0, time.Microsecond, -time.Second} {
+		if ms := chunkDataSizeMaxTimeMS(maxTime); ms != 1 {
+			t.Errorf("Expected a maxTimeMS of 1 for %v, got %d", maxTime, ms)
+		}
+	}
+}
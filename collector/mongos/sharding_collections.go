@@ -0,0 +1,244 @@
+package collector_mongos
+
+import (
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+var (
+	shardingCollectionChunks = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding",
+		Name:      "collection_chunks",
+		Help:      "The number of chunks of a sharded collection per shard",
+	}, []string{"ns", "shard"})
+	shardingCollectionChunksBalanced = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding",
+		Name:      "collection_chunks_is_balanced",
+		Help:      "Boolean reporting if the chunks of a sharded collection are balanced across shards (1 = yes/0 = no)",
+	}, []string{"ns"})
+	shardingCollectionJumboChunks = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding",
+		Name:      "collection_jumbo_chunks",
+		Help:      "The number of chunks of a sharded collection flagged as jumbo per shard, the balancer does not move jumbo chunks",
+	}, []string{"ns", "shard"})
+)
+
+// ShardingCollectionInfo represents a document of config.collections
+type ShardingCollectionInfo struct {
+	Ns      string       `bson:"_id"`
+	UUID    *bson.Binary `bson:"uuid,omitempty"`
+	Key     bson.Raw     `bson:"key"`
+	Dropped bool         `bson:"dropped"`
+}
+
+type shardingCollectionChunksId struct {
+	Ns    string       `bson:"ns"`
+	UUID  *bson.Binary `bson:"uuid,omitempty"`
+	Shard string       `bson:"shard"`
+}
+
+type shardingCollectionChunksCount struct {
+	Id     shardingCollectionChunksId `bson:"_id"`
+	Chunks float64                    `bson:"count"`
+	Jumbo  float64                    `bson:"jumbo"`
+}
+
+// ShardingCollectionStats keeps the chunk distribution and balance of every sharded collection
+type ShardingCollectionStats struct {
+	// Chunks is the number of chunks per namespace and shard
+	Chunks map[string]map[string]float64
+	// JumboChunks is the number of chunks flagged as jumbo per namespace and shard
+	JumboChunks map[string]map[string]float64
+	// Balanced reports per namespace if its chunks are balanced across shards
+	Balanced map[string]bool
+}
+
+// chunkMigrationThreshold returns the difference between the number of chunks on
+// the shards with the most and the fewest chunks of a collection that makes the
+// balancer migrate chunks, see
+// https://docs.mongodb.com/manual/core/sharding-balancer-administration/#migration-thresholds
+func chunkMigrationThreshold(totalChunks float64) float64 {
+	switch {
+	case totalChunks < 20:
+		return 2
+	case totalChunks < 80:
+		return 4
+	}
+	return 8
+}
+
+// isChunksBalanced returns true if the difference between the shards with the
+// most and the fewest chunks is below the migration threshold. Shards without
+// chunks of the collection must be present with a count of 0.
+func isChunksBalanced(shardChunks map[string]float64) bool {
+	var totalChunks, minChunks, maxChunks float64
+	first := true
+	for _, chunks := range shardChunks {
+		totalChunks += chunks
+		if first || chunks < minChunks {
+			minChunks = chunks
+		}
+		if first || chunks > maxChunks {
+			maxChunks = chunks
+		}
+		first = false
+	}
+	return maxChunks-minChunks < chunkMigrationThreshold(totalChunks)
+}
+
+// IsBalanced returns 1 if the chunks of every sharded collection are balanced, 0 otherwise
+func (status *ShardingCollectionStats) IsBalanced() float64 {
+	for _, balanced := range status.Balanced {
+		if !balanced {
+			return 0
+		}
+	}
+	return 1
+}
+
+// Export exports the data to prometheus.
+func (status *ShardingCollectionStats) Export(ch chan<- prometheus.Metric) {
+	shardingCollectionChunks.Reset()
+	shardingCollectionChunksBalanced.Reset()
+	shardingCollectionJumboChunks.Reset()
+
+	for ns, shardChunks := range status.Chunks {
+		for shard, chunks := range shardChunks {
+			shardingCollectionChunks.WithLabelValues(ns, shard).Set(chunks)
+		}
+	}
+	for ns, shardChunks := range status.JumboChunks {
+		for shard, chunks := range shardChunks {
+			shardingCollectionJumboChunks.WithLabelValues(ns, shard).Set(chunks)
+		}
+	}
+	for ns, balanced := range status.Balanced {
+		var value float64
+		if balanced {
+			value = 1
+		}
+		shardingCollectionChunksBalanced.WithLabelValues(ns).Set(value)
+	}
+
+	shardingCollectionChunks.Collect(ch)
+	shardingCollectionChunksBalanced.Collect(ch)
+	shardingCollectionJumboChunks.Collect(ch)
+}
+
+// Describe describes the metrics for prometheus
+func (status *ShardingCollectionStats) Describe(ch chan<- *prometheus.Desc) {
+	shardingCollectionChunks.Describe(ch)
+	shardingCollectionChunksBalanced.Describe(ch)
+	shardingCollectionJumboChunks.Describe(ch)
+}
+
+// GetShardedCollections returns the sharded collections that are not dropped
+func GetShardedCollections(session *mgo.Session) []ShardingCollectionInfo {
+	var collections []ShardingCollectionInfo
+	err := session.DB("config").C("collections").Find(bson.M{"dropped": bson.M{"$ne": true}}).All(&collections)
+	if err != nil {
+		glog.Error("Failed to execute find query on 'config.collections'!")
+	}
+	return collections
+}
+
+// collectionNsByUUID maps the uuid of the sharded collections to their namespace,
+// config.chunks is keyed by the collection uuid instead of ns since version 5.0
+func collectionNsByUUID(collections []ShardingCollectionInfo) map[string]string {
+	nsByUUID := make(map[string]string)
+	for _, collection := range collections {
+		if collection.UUID != nil {
+			nsByUUID[string(collection.UUID.Data)] = collection.Ns
+		}
+	}
+	return nsByUUID
+}
+
+// IsCollectionBalancerCompliant runs balancerCollectionStatus (4.4+) to get the
+// balance of a collection as decided by the balancer itself
+func IsCollectionBalancerCompliant(session *mgo.Session, ns string) (bool, error) {
+	result := struct {
+		BalancerCompliant bool `bson:"balancerCompliant"`
+	}{}
+	err := session.DB("admin").Run(bson.D{{"balancerCollectionStatus", ns}}, &result)
+	if err != nil {
+		glog.Errorf("Failed to get the balancer collection status of %s: %s", ns, err)
+		return false, err
+	}
+	return result.BalancerCompliant, nil
+}
+
+// GetShardingCollectionStatus returns the chunk distribution of the sharded
+// collections per shard and their balance. The balance comes from the migration
+// thresholds, or from one balancerCollectionStatus command per collection when
+// useBalancerStatus is set (4.4+).
+func GetShardingCollectionStatus(session *mgo.Session, shards *[]ShardingTopoShardInfo, useBalancerStatus bool) *ShardingCollectionStats {
+	results := &ShardingCollectionStats{
+		Chunks:      make(map[string]map[string]float64),
+		JumboChunks: make(map[string]map[string]float64),
+		Balanced:    make(map[string]bool),
+	}
+
+	collections := GetShardedCollections(session)
+	nsByUUID := collectionNsByUUID(collections)
+	for _, collection := range collections {
+		results.Chunks[collection.Ns] = make(map[string]float64)
+		results.JumboChunks[collection.Ns] = make(map[string]float64)
+	}
+
+	// shards without chunks of a collection are exported as 0, draining shards
+	// are left out as the balancer moves chunks away from them
+	if shards != nil {
+		for _, shard := range *shards {
+			if shard.Draining {
+				continue
+			}
+			for ns := range results.Chunks {
+				results.Chunks[ns][shard.Shard] = 0
+				results.JumboChunks[ns][shard.Shard] = 0
+			}
+		}
+	}
+
+	var counts []shardingCollectionChunksCount
+	group := bson.M{
+		"_id":   bson.M{"ns": "$ns", "uuid": "$uuid", "shard": "$shard"},
+		"count": bson.M{"$sum": 1},
+		"jumbo": bson.M{"$sum": bson.M{"$cond": []interface{}{bson.M{"$eq": []interface{}{"$jumbo", true}}, 1, 0}}},
+	}
+	err := session.DB("config").C("chunks").Pipe([]bson.M{{"$group": group}}).All(&counts)
+	if err != nil {
+		glog.Error("Failed to execute aggregate query on 'config.chunks'!")
+		return nil
+	}
+	for _, count := range counts {
+		ns := count.Id.Ns
+		if ns == "" && count.Id.UUID != nil {
+			ns = nsByUUID[string(count.Id.UUID.Data)]
+		}
+		shardChunks, ok := results.Chunks[ns]
+		if !ok {
+			continue
+		}
+		shardChunks[count.Id.Shard] = count.Chunks
+		results.JumboChunks[ns][count.Id.Shard] = count.Jumbo
+	}
+
+	for ns, shardChunks := range results.Chunks {
+		if useBalancerStatus {
+			compliant, err := IsCollectionBalancerCompliant(session, ns)
+			if err == nil {
+				results.Balanced[ns] = compliant
+				continue
+			}
+		}
+		results.Balanced[ns] = isChunksBalanced(shardChunks)
+	}
+
+	return results
+}
@@ -0,0 +1,28 @@
+package collector_mongos
+
+import (
+	"testing"
+)
+
+func Test_ChunkMigrationThreshold(t *testing.T) {
+	for totalChunks, threshold := range map[float64]float64{0: 2, 19: 2, 20: 4, 21: 4, 79: 4, 80: 8, 1000: 8} {
+		if chunkMigrationThreshold(totalChunks) != threshold {
+			t.Errorf("Expected threshold %v for %v chunks, got %v", threshold, totalChunks, chunkMigrationThreshold(totalChunks))
+		}
+	}
+}
+
+func Test_IsChunksBalanced(t *testing.T) {
+	if !isChunksBalanced(map[string]float64{"rs1": 5, "rs2": 4}) {
+		t.Error("Expected 5/4 chunks to be balanced")
+	}
+	if isChunksBalanced(map[string]float64{"rs1": 6, "rs2": 0}) {
+		t.Error("Expected 6/0 chunks to be unbalanced")
+	}
+	if !isChunksBalanced(map[string]float64{"rs1": 40, "rs2": 37}) {
+		t.Error("Expected 40/37 chunks to be balanced")
+	}
+	if !isChunksBalanced(map[string]float64{}) {
+		t.Error("Expected a collection without shards to be balanced")
+	}
+}
@@ -0,0 +1,218 @@
+package collector_mongos
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+var (
+	databaseInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "database_info",
+		Help:      "The primary shard of a database and if it is partitioned, which is derived from its sharded collections where config.databases has no partitioned field (6.0+), the value is always 1",
+	}, []string{"db", "primary_shard", "partitioned"})
+	shardingShardUnshardedCollections = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding",
+		Name:      "shard_unsharded_collections",
+		Help:      "The number of unsharded collections stored on a shard as the primary shard of their database",
+	}, []string{"shard"})
+	shardingShardUnshardedCollectionsSizeBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding",
+		Name:      "shard_unsharded_collections_size_bytes",
+		Help:      "The total uncompressed data size of the unsharded collections stored on a shard as the primary shard of their database",
+	}, []string{"shard"})
+)
+
+// ShardingDatabaseInfo represents a document of config.databases
+type ShardingDatabaseInfo struct {
+	Name        string `bson:"_id"`
+	Primary     string `bson:"primary"`
+	Partitioned bool   `bson:"partitioned"`
+}
+
+// ShardingDatabaseStats keeps the databases of the cluster and their primary shard
+type ShardingDatabaseStats struct {
+	Databases []ShardingDatabaseInfo
+	// Sharded is the set of databases with sharded collections
+	Sharded map[string]bool
+}
+
+// Export exports the data to prometheus.
+func (status *ShardingDatabaseStats) Export(ch chan<- prometheus.Metric) {
+	databaseInfo.Reset()
+	for _, database := range status.Databases {
+		// config.databases no longer has the partitioned field since 6.0, where
+		// every database can have sharded collections
+		partitioned := database.Partitioned || status.Sharded[database.Name]
+		databaseInfo.WithLabelValues(database.Name, database.Primary, strconv.FormatBool(partitioned)).Set(1)
+	}
+	databaseInfo.Collect(ch)
+}
+
+// Describe describes the metrics for prometheus
+func (status *ShardingDatabaseStats) Describe(ch chan<- *prometheus.Desc) {
+	databaseInfo.Describe(ch)
+}
+
+// GetShardingDatabases returns the databases of config.databases, the admin
+// and config databases are always stored on the config servers
+func GetShardingDatabases(session *mgo.Session) []ShardingDatabaseInfo {
+	var databases []ShardingDatabaseInfo
+	err := session.DB("config").C("databases").Find(bson.M{"_id": bson.M{"$nin": []string{"admin", "config"}}}).All(&databases)
+	if err != nil {
+		glog.Error("Failed to execute find query on 'config.databases'!")
+	}
+	return databases
+}
+
+// shardedDatabases returns the set of databases with sharded collections
+func shardedDatabases(collections []ShardingCollectionInfo) map[string]bool {
+	databases := make(map[string]bool)
+	for _, collection := range collections {
+		databases[strings.SplitN(collection.Ns, ".", 2)[0]] = true
+	}
+	return databases
+}
+
+// GetShardingDatabaseStatus returns the databases of the cluster and their primary shard
+func GetShardingDatabaseStatus(session *mgo.Session) *ShardingDatabaseStats {
+	return &ShardingDatabaseStats{
+		Databases: GetShardingDatabases(session),
+		Sharded:   shardedDatabases(GetShardedCollections(session)),
+	}
+}
+
+// ShardingUnshardedCollectionStats keeps the number and size of the unsharded
+// collections per primary shard
+type ShardingUnshardedCollectionStats struct {
+	Collections map[string]float64
+	SizeBytes   map[string]float64
+}
+
+// Export exports the data to prometheus.
+func (status *ShardingUnshardedCollectionStats) Export(ch chan<- prometheus.Metric) {
+	shardingShardUnshardedCollections.Reset()
+	shardingShardUnshardedCollectionsSizeBytes.Reset()
+
+	for shard, collections := range status.Collections {
+		shardingShardUnshardedCollections.WithLabelValues(shard).Set(collections)
+	}
+	for shard, size := range status.SizeBytes {
+		shardingShardUnshardedCollectionsSizeBytes.WithLabelValues(shard).Set(size)
+	}
+
+	shardingShardUnshardedCollections.Collect(ch)
+	shardingShardUnshardedCollectionsSizeBytes.Collect(ch)
+}
+
+// Describe describes the metrics for prometheus
+func (status *ShardingUnshardedCollectionStats) Describe(ch chan<- *prometheus.Desc) {
+	shardingShardUnshardedCollections.Describe(ch)
+	shardingShardUnshardedCollectionsSizeBytes.Describe(ch)
+}
+
+// unshardedCollectionNames returns the collections of a database that are
+// not sharded, system collections are left out
+func unshardedCollectionNames(db string, names []string, sharded map[string]bool) []string {
+	var unsharded []string
+	for _, name := range names {
+		if strings.HasPrefix(name, "system.") || sharded[db+"."+name] {
+			continue
+		}
+		unsharded = append(unsharded, name)
+	}
+	return unsharded
+}
+
+// sumUnshardedCollections sums the number and size of the unsharded
+// collections per primary shard from their size per database and collection.
+// Primary shards without unsharded collections are reported as 0.
+func sumUnshardedCollections(databases []ShardingDatabaseInfo, sizes map[string]map[string]float64) *ShardingUnshardedCollectionStats {
+	results := &ShardingUnshardedCollectionStats{
+		Collections: make(map[string]float64),
+		SizeBytes:   make(map[string]float64),
+	}
+	for _, database := range databases {
+		// report 0 for primary shards that only have sharded collections
+		if _, ok := results.Collections[database.Primary]; !ok {
+			results.Collections[database.Primary] = 0
+			results.SizeBytes[database.Primary] = 0
+		}
+		for _, size := range sizes[database.Name] {
+			results.Collections[database.Primary]++
+			results.SizeBytes[database.Primary] += size
+		}
+	}
+	return results
+}
+
+// listCollectionNames returns the names of the collections of a database,
+// views are left out. The type field is only returned since version 3.4,
+// which added views.
+func listCollectionNames(session *mgo.Session, db string) ([]string, error) {
+	result := struct {
+		Cursor struct {
+			FirstBatch []bson.Raw `bson:"firstBatch"`
+			Id         int64      `bson:"id"`
+		} `bson:"cursor"`
+	}{}
+	filter := bson.M{"$or": []bson.M{
+		{"type": "collection"},
+		{"type": bson.M{"$exists": false}},
+	}}
+	err := session.DB(db).Run(bson.D{{"listCollections", 1}, {"filter", filter}}, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	iter := session.DB(db).C("$cmd.listCollections").NewIter(session, result.Cursor.FirstBatch, result.Cursor.Id, nil)
+	collection := struct {
+		Name string `bson:"name"`
+	}{}
+	for iter.Next(&collection) {
+		names = append(names, collection.Name)
+	}
+	return names, iter.Close()
+}
+
+// GetShardingUnshardedCollectionStatus lists the collections of every database
+// through mongos and sums the collStats size of the unsharded ones per primary
+// shard. Views and system collections are left out.
+func GetShardingUnshardedCollectionStatus(session *mgo.Session) *ShardingUnshardedCollectionStats {
+	sharded := make(map[string]bool)
+	for _, collection := range GetShardedCollections(session) {
+		sharded[collection.Ns] = true
+	}
+
+	databases := GetShardingDatabases(session)
+	sizes := make(map[string]map[string]float64)
+	for _, database := range databases {
+		names, err := listCollectionNames(session, database.Name)
+		if err != nil {
+			glog.Errorf("Failed to list the collections of %s: %s", database.Name, err)
+			continue
+		}
+		sizes[database.Name] = make(map[string]float64)
+		for _, name := range unshardedCollectionNames(database.Name, names, sharded) {
+			collStats := struct {
+				Size float64 `bson:"size"`
+			}{}
+			err := session.DB(database.Name).Run(bson.D{{"collStats", name}}, &collStats)
+			if err != nil {
+				glog.Errorf("Failed to get collStats of %s.%s: %s", database.Name, name, err)
+				continue
+			}
+			sizes[database.Name][name] = collStats.Size
+		}
+	}
+
+	return sumUnshardedCollections(databases, sizes)
+}
@@ -0,0 +1,40 @@
+package collector_mongos
+
+import (
+	"testing"
+)
+
+func Test_UnshardedCollectionNames(t *testing.T) {
+	sharded := map[string]bool{"app.events": true}
+	names := unshardedCollectionNames("app", []string{"users", "events", "system.profile", "system.js"}, sharded)
+	if len(names) != 1 || names[0] != "users" {
+		t.Errorf("Expected only users to be unsharded, got %v", names)
+	}
+}
+
+func Test_SumUnshardedCollections(t *testing.T) {
+	databases := []ShardingDatabaseInfo{
+		{Name: "app", Primary: "rs0"},
+		{Name: "logs", Primary: "rs0"},
+		{Name: "events", Primary: "rs1"},
+	}
+	sizes := map[string]map[string]float64{
+		"app":    {"users": 1000, "sessions": 500},
+		"logs":   {"audit": 250},
+		"events": {},
+	}
+	results := sumUnshardedCollections(databases, sizes)
+	if results.Collections["rs0"] != 3 || results.SizeBytes["rs0"] != 1750 {
+		t.Errorf("Unexpected unsharded collections on rs0: %v collections, %v bytes", results.Collections["rs0"], results.SizeBytes["rs0"])
+	}
+	if collections, ok := results.Collections["rs1"]; !ok || collections != 0 || results.SizeBytes["rs1"] != 0 {
+		t.Errorf("Expected rs1 with only sharded collections to be reported as 0, got %v", results.Collections)
+	}
+}
+
+func Test_ShardedDatabases(t *testing.T) {
+	databases := shardedDatabases([]ShardingCollectionInfo{{Ns: "app.events"}, {Ns: "app.users"}, {Ns: "logs.audit"}})
+	if len(databases) != 2 || !databases["app"] || !databases["logs"] {
+		t.Errorf("Unexpected sharded databases %v", databases)
+	}
+}
@@ -0,0 +1,130 @@
+package collector_mongos
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	chunkMigrationBuckets = prometheus.ExponentialBuckets(0.01, 4, 10)
+
+	chunkMigrationStepSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "chunk_migration_step_seconds",
+		Help:      "The duration of the steps of the chunk migrations reported in the moveChunk.from and moveChunk.to changelog events, the step label is the event side and step number",
+		Buckets:   chunkMigrationBuckets,
+	}, []string{"step", "ns"})
+	chunkMigrationDurationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "chunk_migration_duration_seconds",
+		Help:      "The total duration of the chunk migrations as the sum of the donor steps of the moveChunk.from changelog events",
+		Buckets:   chunkMigrationBuckets,
+	}, []string{"ns", "result"})
+	chunkMigrationFailuresTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "chunk_migration_failures_total",
+		Help:      "The total number of failed chunk migrations reported in the changelog by event and class of the error message",
+	}, []string{"ns", "event", "error"})
+)
+
+var (
+	chunkMigrationStepRegexp = regexp.MustCompile(`^step (\d+) of \d+$`)
+
+	// chunkMigrationErrorClasses maps whole words of the migration errmsg,
+	// including the names of the server error codes, to a class. The first
+	// match wins.
+	chunkMigrationErrorClasses = []struct {
+		pattern *regexp.Regexp
+		class   string
+	}{
+		{regexp.MustCompile(`\b(timed out|time out|timeout|exceededtimelimit|maxtimemsexpired|networktimeout)\b`), "timeout"},
+		{regexp.MustCompile(`\b(interrupted|interruptedatshutdown|interruptedduetoreplstatechange)\b`), "interrupted"},
+		{regexp.MustCompile(`\b(lock|locks|locked|lockbusy|locktimeout|conflictinglockfault)\b`), "lock"},
+		{regexp.MustCompile(`\b(stale|staleconfig|staleshardversion|staleepoch|shard version|collection version)\b`), "stale_config"},
+		{regexp.MustCompile(`\b(catch up|catchup)\b`), "catch_up"},
+		{regexp.MustCompile(`\b(clone|cloning|cloned)\b`), "clone"},
+		{regexp.MustCompile(`\b(critical section|commit|committing)\b`), "commit"},
+		{regexp.MustCompile(`\b(duplicate key|duplicatekey|e11000)\b`), "duplicate_key"},
+		{regexp.MustCompile(`\b(range deleter|range deletion|orphan|orphans|orphaned)\b`), "range_deletion"},
+	}
+)
+
+// chunkMigrationErrorClass returns a low cardinality class of a migration errmsg
+func chunkMigrationErrorClass(errmsg string) string {
+	errmsg = strings.ToLower(errmsg)
+	for _, errorClass := range chunkMigrationErrorClasses {
+		if errorClass.pattern.MatchString(errmsg) {
+			return errorClass.class
+		}
+	}
+	return "other"
+}
+
+// chunkMigrationFailed reports whether a moveChunk changelog event records a
+// failed migration, from its note or its errmsg
+func chunkMigrationFailed(details *ShardingChangelogEntryDetails) bool {
+	return (details.Note != "" && details.Note != "success") || details.Errmsg != ""
+}
+
+// chunkMigrationSteps returns the step timings in seconds of a moveChunk
+// changelog event by step number, the details carry them as "step N of M" in
+// milliseconds
+func chunkMigrationSteps(details map[string]interface{}) map[int]float64 {
+	steps := make(map[int]float64)
+	for key, value := range details {
+		match := chunkMigrationStepRegexp.FindStringSubmatch(key)
+		if match == nil {
+			continue
+		}
+		step, err := strconv.Atoi(match[1])
+		if err != nil {
+			continue
+		}
+		switch millis := value.(type) {
+		case int:
+			steps[step] = float64(millis) / 1000
+		case int64:
+			steps[step] = float64(millis) / 1000
+		case float64:
+			steps[step] = millis / 1000
+		}
+	}
+	return steps
+}
+
+// observeChunkMigration records the step timings and failures of the moveChunk changelog events
+func observeChunkMigration(entry *ShardingChangelogEntry) {
+	var side string
+	switch entry.What {
+	case "moveChunk.from":
+		side = "from"
+	case "moveChunk.to":
+		side = "to"
+	case "moveChunk.error":
+		chunkMigrationFailuresTotal.WithLabelValues(entry.Ns, entry.What, chunkMigrationErrorClass(entry.Details.Errmsg)).Inc()
+		return
+	default:
+		return
+	}
+
+	var total float64
+	for step, seconds := range chunkMigrationSteps(entry.Details.Other) {
+		chunkMigrationStepSeconds.WithLabelValues(side+"_"+strconv.Itoa(step), entry.Ns).Observe(seconds)
+		total += seconds
+	}
+
+	failed := chunkMigrationFailed(&entry.Details)
+	if failed {
+		chunkMigrationFailuresTotal.WithLabelValues(entry.Ns, entry.What, chunkMigrationErrorClass(entry.Details.Errmsg)).Inc()
+	}
+	if side == "from" {
+		result := "success"
+		if failed {
+			result = "failed"
+		}
+		chunkMigrationDurationSeconds.WithLabelValues(entry.Ns, result).Observe(total)
+	}
+}
@@ -0,0 +1,56 @@
+package collector_mongos
+
+import (
+	"testing"
+)
+
+func Test_ChunkMigrationSteps(t *testing.T) {
+	steps := chunkMigrationSteps(map[string]interface{}{
+		"step 1 of 6": 0,
+		"step 2 of 6": 1500,
+		"step 4 of 6": int64(30000),
+		"min":         map[string]interface{}{"_id": 1},
+		"to":          "rs2",
+	})
+	if len(steps) != 3 {
+		t.Fatalf("Expected 3 steps, got %d", len(steps))
+	}
+	if steps[2] != 1.5 || steps[4] != 30 {
+		t.Errorf("Unexpected step timings %v", steps)
+	}
+}
+
+func Test_ChunkMigrationErrorClass(t *testing.T) {
+	for errmsg, class := range map[string]string{
+		"moveChunk command failed: Operation timed out": "timeout",
+		"Failed to refresh the collection metadata":     "other",
+		"Data transfer error: catchup failed":           "catch_up",
+		"LockBusy: could not acquire collection lock":   "lock",
+		"StaleConfig: shard version mismatch":           "stale_config",
+		"failed to block writes on the donor":           "other",
+		"clock skew detected":                           "other",
+		"unsupported featureCompatibilityVersion":       "other",
+		"": "other",
+	} {
+		if chunkMigrationErrorClass(errmsg) != class {
+			t.Errorf("Expected class %s for %q, got %s", class, errmsg, chunkMigrationErrorClass(errmsg))
+		}
+	}
+}
+
+func Test_ChunkMigrationFailed(t *testing.T) {
+	for _, test := range []struct {
+		details ShardingChangelogEntryDetails
+		failed  bool
+	}{
+		{ShardingChangelogEntryDetails{}, false},
+		{ShardingChangelogEntryDetails{Note: "success"}, false},
+		{ShardingChangelogEntryDetails{Note: "aborted"}, true},
+		{ShardingChangelogEntryDetails{Note: "success", Errmsg: "Operation timed out"}, true},
+		{ShardingChangelogEntryDetails{Errmsg: "Operation timed out"}, true},
+	} {
+		if chunkMigrationFailed(&test.details) != test.failed {
+			t.Errorf("Expected failed=%v for %+v", test.failed, test.details)
+		}
+	}
+}
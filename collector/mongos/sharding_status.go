@@ -2,7 +2,9 @@ package collector_mongos
 
 import (
 	"time"
+	"strconv"
 	"strings"
+	"github.com/percona/mongodb_exporter/shared"
 	"github.com/golang/glog"
 	"github.com/prometheus/client_golang/prometheus"
 	"gopkg.in/mgo.v2"
@@ -20,7 +22,7 @@ var (
 			Namespace:	Namespace,
 			Subsystem:	"sharding",
 			Name:		"chunks_is_balanced",
-			Help:		"Boolean reporting if cluster chunks are evenly balanced across shards (1 = yes/0 = no)",
+			Help:		"Boolean reporting if the chunks of every sharded collection are balanced across shards (1 = yes/0 = no)",
 	})
 	mongosUpSecs = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Namespace:      Namespace,
@@ -46,6 +48,31 @@ var (
 			Name:		"balancer_lock_state",
 			Help:		"The state of the Cluster balancer lock (-1 = none/0 = unlocked/1 = contention/2 = locked)",
 	}, []string{"name"})
+	routerInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace:	Namespace,
+			Name:		"router_info",
+			Help:		"The version of every Mongos registered in config.mongos, the value is always 1",
+	}, []string{"name", "version"})
+	routerLastPingAgeSeconds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace:	Namespace,
+			Name:		"router_last_ping_age_seconds",
+			Help:		"The time in seconds since the last Mongos ping to the Cluster config servers",
+	}, []string{"name"})
+	routerStale = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace:	Namespace,
+			Name:		"router_stale",
+			Help:		"Boolean reporting if the last Mongos ping is older than the configured staleness cut-off (1 = stale/0 = alive)",
+	}, []string{"name"})
+	routerWaiting = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace:	Namespace,
+			Name:		"router_waiting",
+			Help:		"Boolean reporting if the Mongos is waiting for the config servers (1 = waiting/0 = not waiting)",
+	}, []string{"name"})
+	routerVersionMismatch = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace:	Namespace,
+			Name:		"router_version_mismatch",
+			Help:		"Boolean reporting if the Mongos runs a different version than the newest Mongos that pinged within the staleness cut-off (1 = differs/0 = matches)",
+	}, []string{"name"})
 )
 
 type MongosInfo struct {
@@ -64,18 +91,65 @@ type MongosBalancerLock struct {
 	Why	string		`bson:"why"`
 }
 
+// ShardingStatusOpts keeps the options of the sharding status collection
+type ShardingStatusOpts struct {
+	ServerVersion		string
+	ChangelogWindow		time.Duration
+	// MongosStaleAfter is the age of the last ping after which a Mongos is reported as stale
+	MongosStaleAfter	time.Duration
+	// BalancerCollectionStatus runs balancerCollectionStatus for every sharded collection (4.4+)
+	BalancerCollectionStatus	bool
+}
+
 type ShardingStats struct {
 	IsBalanced	float64	
 	BalancerEnabled	float64
 	Changelog	*ShardingChangelogStats	
 	Topology	*ShardingTopoStats
+	Collections	*ShardingCollectionStats
+	Balancer	*ShardingBalancerStats
+	Zones		*ShardingZoneStats
+	Databases	*ShardingDatabaseStats
 	BalancerLock	*MongosBalancerLock
 	Mongos		*[]MongosInfo
+	MongosStaleAfter	time.Duration
+}
+
+func boolValue(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+// isNewerVersion returns true if version is newer than other
+func isNewerVersion(version string, other string) bool {
+	var parts [3]int
+	for i, part := range strings.SplitN(strings.SplitN(version, "-", 2)[0], ".", 3) {
+		parts[i], _ = strconv.Atoi(part)
+	}
+	return !shared.IsVersionGreater(other, parts[0], parts[1], parts[2])
+}
+
+// newestMongosVersion returns the newest version of the Mongos that pinged
+// within staleAfter, the version every Mongos is compared with. It is empty
+// when no Mongos pinged recently.
+func newestMongosVersion(mongos []MongosInfo, staleAfter time.Duration) string {
+	var newest string
+	for _, info := range mongos {
+		if info.MongoVersion == "" || time.Since(info.Ping) > staleAfter {
+			continue
+		}
+		if newest == "" || isNewerVersion(info.MongoVersion, newest) {
+			newest = info.MongoVersion
+		}
+	}
+	return newest
 }
 
 func GetMongosInfo(session *mgo.Session) *[]MongosInfo {
 	mongosInfo := []MongosInfo{}
-	err := session.DB("config").C("mongos").Find(bson.M{ "ping" : bson.M{ "$gte" : time.Now().Add(-10 * time.Minute) } }).All(&mongosInfo)
+	err := session.DB("config").C("mongos").Find(bson.M{}).All(&mongosInfo)
 	if err != nil {
 		glog.Error("Failed to execute find query on 'config.mongos'!")
 	}
@@ -91,52 +165,6 @@ func GetMongosBalancerLock(session *mgo.Session) *MongosBalancerLock {
 	return balancerLock
 }
 
-func IsBalancerEnabled(session *mgo.Session) float64 {
-	balancerConfig := struct {
-		Stopped	bool	`bson:"stopped"`
-	}{}
-	err := session.DB("config").C("settings").Find(bson.M{ "_id" : "balancer" }).One(&balancerConfig)
-	if err != nil {
-		return 1
-	}
-	if balancerConfig.Stopped == true {
-		return 0
-	}
-	return 1
-}
-
-func IsClusterBalanced(session *mgo.Session) float64 {
-	// Different thresholds based on size
-	// http://docs.mongodb.org/manual/core/sharding-internals/#sharding-migration-thresholds
-	var threshold float64 = 8
-	totalChunkCount := GetTotalChunks(session)
-	if totalChunkCount < 20 {
-		threshold = 2
-	} else if totalChunkCount < 80 && totalChunkCount > 21 {
-		threshold = 4
-	}
-
-	var minChunkCount float64 = -1
-	var maxChunkCount float64 = 0
-	shardChunkInfoAll := GetTotalChunksByShard(session)
-	for _, shard := range *shardChunkInfoAll {
-		if shard.Chunks > maxChunkCount {
-			maxChunkCount = shard.Chunks
-		}
-		if minChunkCount == -1 || shard.Chunks < minChunkCount {
-			minChunkCount = shard.Chunks
-		}
-	}
-
-	// return true if the difference between the min and max is < the thresold
-	chunkDifference := maxChunkCount - minChunkCount
-	if chunkDifference < threshold {
-		return 1
-	}
-
-	return 0
-}
-
 func (status *ShardingStats) Export(ch chan<- prometheus.Metric) {
 	if status.Changelog != nil {
 		status.Changelog.Export(ch)
@@ -144,21 +172,58 @@ func (status *ShardingStats) Export(ch chan<- prometheus.Metric) {
 	if status.Topology != nil {
 		status.Topology.Export(ch)
 	}
-	if status.Mongos != nil && status.BalancerLock != nil {
-		mongosBalancerLockWho := strings.Split(status.BalancerLock.Who, ":")
-		mongosBalancerLockHostPort := mongosBalancerLockWho[0] + ":" + mongosBalancerLockWho[1]
-		mongosBalancerLockTimestamp.WithLabelValues(mongosBalancerLockHostPort).Set(float64(status.BalancerLock.When.Unix()))
+	if status.Collections != nil {
+		status.Collections.Export(ch)
+	}
+	if status.Balancer != nil {
+		status.Balancer.Export(ch)
+	}
+	if status.Zones != nil {
+		status.Zones.Export(ch)
+	}
+	if status.Databases != nil {
+		status.Databases.Export(ch)
+	}
+	if status.Mongos != nil {
+		// the balancer lock is only read before version 3.4, see GetShardingStatus
+		var mongosBalancerLockHostPort string
+		if status.BalancerLock != nil {
+			mongosBalancerLockWho := strings.Split(status.BalancerLock.Who, ":")
+			mongosBalancerLockHostPort = mongosBalancerLockWho[0] + ":" + mongosBalancerLockWho[1]
+			mongosBalancerLockTimestamp.WithLabelValues(mongosBalancerLockHostPort).Set(float64(status.BalancerLock.When.Unix()))
+		}
+		routerInfo.Reset()
+		routerLastPingAgeSeconds.Reset()
+		routerStale.Reset()
+		routerWaiting.Reset()
+		routerVersionMismatch.Reset()
+		newestVersion := newestMongosVersion(*status.Mongos, status.MongosStaleAfter)
 		for _, mongos := range *status.Mongos {
+			pingAge := time.Since(mongos.Ping)
+			routerInfo.WithLabelValues(mongos.Name, mongos.MongoVersion).Set(1)
+			routerLastPingAgeSeconds.WithLabelValues(mongos.Name).Set(pingAge.Seconds())
+			routerStale.WithLabelValues(mongos.Name).Set(boolValue(pingAge > status.MongosStaleAfter))
+			routerWaiting.WithLabelValues(mongos.Name).Set(boolValue(mongos.Waiting))
+			if newestVersion != "" {
+				routerVersionMismatch.WithLabelValues(mongos.Name).Set(boolValue(mongos.MongoVersion != newestVersion))
+			}
+
+			// the legacy metrics only report the Mongos that pinged recently
+			if pingAge > status.MongosStaleAfter {
+				continue
+			}
 			mongosUpSecs.WithLabelValues(mongos.Name).Set(mongos.Up)
 			mongosPing.WithLabelValues(mongos.Name).Set(float64(mongos.Ping.Unix()))
-			mongosBalancerLockState.WithLabelValues(mongos.Name).Set(-1)
-			if mongos.Name == mongosBalancerLockHostPort {
-				mongosBalancerLockState.WithLabelValues(mongos.Name).Set(status.BalancerLock.State)
+			if status.BalancerLock != nil {
+				mongosBalancerLockState.WithLabelValues(mongos.Name).Set(-1)
+				if mongos.Name == mongosBalancerLockHostPort {
+					mongosBalancerLockState.WithLabelValues(mongos.Name).Set(status.BalancerLock.State)
+				}
 			}
 		}
 	}
-	balancerIsEnabled.Set(status.IsBalanced)
-	balancerChunksBalanced.Set(status.BalancerEnabled)
+	balancerIsEnabled.Set(status.BalancerEnabled)
+	balancerChunksBalanced.Set(status.IsBalanced)
 
 	balancerIsEnabled.Collect(ch)
 	balancerChunksBalanced.Collect(ch)
@@ -166,6 +231,11 @@ func (status *ShardingStats) Export(ch chan<- prometheus.Metric) {
 	mongosPing.Collect(ch)
 	mongosBalancerLockState.Collect(ch)
 	mongosBalancerLockTimestamp.Collect(ch)
+	routerInfo.Collect(ch)
+	routerLastPingAgeSeconds.Collect(ch)
+	routerStale.Collect(ch)
+	routerWaiting.Collect(ch)
+	routerVersionMismatch.Collect(ch)
 }
 
 func (status *ShardingStats) Describe(ch chan<- *prometheus.Desc) {
@@ -175,23 +245,52 @@ func (status *ShardingStats) Describe(ch chan<- *prometheus.Desc) {
 	if status.Topology != nil {
 		status.Topology.Describe(ch)
 	}
+	if status.Collections != nil {
+		status.Collections.Describe(ch)
+	}
+	if status.Balancer != nil {
+		status.Balancer.Describe(ch)
+	}
+	if status.Zones != nil {
+		status.Zones.Describe(ch)
+	}
+	if status.Databases != nil {
+		status.Databases.Describe(ch)
+	}
 	balancerIsEnabled.Describe(ch)
 	balancerChunksBalanced.Describe(ch)
 	mongosUpSecs.Describe(ch)
 	mongosPing.Describe(ch)
 	mongosBalancerLockState.Describe(ch)
 	mongosBalancerLockTimestamp.Describe(ch)
+	routerInfo.Describe(ch)
+	routerLastPingAgeSeconds.Describe(ch)
+	routerStale.Describe(ch)
+	routerWaiting.Describe(ch)
+	routerVersionMismatch.Describe(ch)
 }
 
-func GetShardingStatus(session *mgo.Session) *ShardingStats {
+func GetShardingStatus(session *mgo.Session, opts ShardingStatusOpts) *ShardingStats {
 	results := &ShardingStats{}
 
-	results.IsBalanced = IsClusterBalanced(session)
-	results.BalancerEnabled = IsBalancerEnabled(session)
-	results.Changelog = GetShardingChangelogStatus(session) 
+	results.Balancer = GetShardingBalancerStatus(session)
+	results.BalancerEnabled = results.Balancer.IsEnabled()
+	results.Changelog = GetShardingChangelogStatus(session, opts.ChangelogWindow) 
 	results.Topology = GetShardingTopoStatus(session)
+	useBalancerStatus := opts.BalancerCollectionStatus && shared.IsVersionGreater(opts.ServerVersion, 4, 4, 0)
+	results.Collections = GetShardingCollectionStatus(session, results.Topology.Shards, useBalancerStatus)
+	results.Zones = GetShardingZoneStatus(session, results.Topology.Shards)
+	results.Databases = GetShardingDatabaseStatus(session)
+	results.IsBalanced = 1
+	if results.Collections != nil {
+		results.IsBalanced = results.Collections.IsBalanced()
+	}
 	results.Mongos = GetMongosInfo(session)
-	results.BalancerLock = GetMongosBalancerLock(session)
+	results.MongosStaleAfter = opts.MongosStaleAfter
+	// the config.locks balancer document no longer reflects the balancer activity since version 3.4
+	if results.Balancer.Status == nil {
+		results.BalancerLock = GetMongosBalancerLock(session)
+	}
 
 	return results
 }
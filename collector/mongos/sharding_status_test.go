@@ -0,0 +1,25 @@
+package collector_mongos
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_NewestMongosVersion(t *testing.T) {
+	now := time.Now()
+	mongos := []MongosInfo{
+		{Name: "router1:27017", Ping: now, MongoVersion: "4.2.12"},
+		{Name: "router2:27017", Ping: now, MongoVersion: "4.4.0-rc1"},
+		{Name: "router3:27017", Ping: now, MongoVersion: "4.2.24"},
+		{Name: "router4:27017", Ping: now.Add(-time.Hour), MongoVersion: "5.0.3"},
+	}
+	if version := newestMongosVersion(mongos, 10*time.Minute); version != "4.4.0-rc1" {
+		t.Errorf("Expected the newest version of the recent routers to be 4.4.0-rc1, got %q", version)
+	}
+	if version := newestMongosVersion(mongos[3:], 10*time.Minute); version != "" {
+		t.Errorf("Expected no reference version without a recent router, got %q", version)
+	}
+	if !isNewerVersion("4.10.0", "4.9.1") || isNewerVersion("4.2.12", "4.2.12") {
+		t.Error("Expected versions to be compared numerically")
+	}
+}
@@ -50,6 +50,7 @@ type ShardingTopoShardInfo struct {
 	Shard		string	`bson:"_id"`
 	Host		string	`bson:"host"`
 	Draining	bool	`bson:"draining",omitifempty`
+	Tags		[]string	`bson:"tags"`
 }
 
 type ShardingTopoChunkInfo struct {
@@ -0,0 +1,237 @@
+package collector_mongos
+
+import (
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+var (
+	shardingZoneRanges = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding",
+		Name:      "zone_ranges",
+		Help:      "The number of shard key ranges assigned to a zone per sharded collection",
+	}, []string{"ns", "zone"})
+	shardingZoneShards = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding",
+		Name:      "zone_shards",
+		Help:      "The number of shards associated with a zone",
+	}, []string{"zone"})
+	shardingZoneChunks = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding",
+		Name:      "zone_chunks",
+		Help:      "The number of chunks within the ranges of a zone per sharded collection and shard",
+	}, []string{"ns", "zone", "shard"})
+	shardingZoneMisplacedChunks = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "sharding",
+		Name:      "zone_misplaced_chunks",
+		Help:      "The number of chunks within the ranges of a zone that are on a shard outside of the zone, the balancer should move them",
+	}, []string{"ns", "zone"})
+)
+
+// ShardingZoneRange represents a document of config.tags
+type ShardingZoneRange struct {
+	Ns   string   `bson:"ns"`
+	Min  bson.Raw `bson:"min"`
+	Max  bson.Raw `bson:"max"`
+	Zone string   `bson:"tag"`
+}
+
+type shardingZoneKey struct {
+	Ns   string
+	Zone string
+}
+
+type shardingZoneChunksKey struct {
+	Ns    string
+	Zone  string
+	Shard string
+}
+
+// ShardingZoneStats keeps the zone ranges and the placement of the chunks within them
+type ShardingZoneStats struct {
+	Ranges          map[shardingZoneKey]float64
+	ZoneShards      map[string]float64
+	Chunks          map[shardingZoneChunksKey]float64
+	MisplacedChunks map[shardingZoneKey]float64
+}
+
+// Export exports the data to prometheus.
+func (status *ShardingZoneStats) Export(ch chan<- prometheus.Metric) {
+	shardingZoneRanges.Reset()
+	shardingZoneShards.Reset()
+	shardingZoneChunks.Reset()
+	shardingZoneMisplacedChunks.Reset()
+
+	for key, ranges := range status.Ranges {
+		shardingZoneRanges.WithLabelValues(key.Ns, key.Zone).Set(ranges)
+	}
+	for zone, shards := range status.ZoneShards {
+		shardingZoneShards.WithLabelValues(zone).Set(shards)
+	}
+	for key, chunks := range status.Chunks {
+		shardingZoneChunks.WithLabelValues(key.Ns, key.Zone, key.Shard).Set(chunks)
+	}
+	for key, chunks := range status.MisplacedChunks {
+		shardingZoneMisplacedChunks.WithLabelValues(key.Ns, key.Zone).Set(chunks)
+	}
+
+	shardingZoneRanges.Collect(ch)
+	shardingZoneShards.Collect(ch)
+	shardingZoneChunks.Collect(ch)
+	shardingZoneMisplacedChunks.Collect(ch)
+}
+
+// Describe describes the metrics for prometheus
+func (status *ShardingZoneStats) Describe(ch chan<- *prometheus.Desc) {
+	shardingZoneRanges.Describe(ch)
+	shardingZoneShards.Describe(ch)
+	shardingZoneChunks.Describe(ch)
+	shardingZoneMisplacedChunks.Describe(ch)
+}
+
+// GetShardingZoneRanges returns the zone ranges of config.tags
+func GetShardingZoneRanges(session *mgo.Session) []ShardingZoneRange {
+	var ranges []ShardingZoneRange
+	err := session.DB("config").C("tags").Find(bson.M{}).All(&ranges)
+	if err != nil {
+		glog.Error("Failed to execute find query on 'config.tags'!")
+	}
+	return ranges
+}
+
+// shardingZoneRangeChunks is a result document of the zone chunks pipeline,
+// Range is the index of the zone range in the ranges of config.tags
+type shardingZoneRangeChunks struct {
+	Id struct {
+		Range int    `bson:"range"`
+		Shard string `bson:"shard"`
+	} `bson:"_id"`
+	Chunks float64 `bson:"count"`
+}
+
+// zoneShardSets returns the shards of every zone from the tags of the shards
+func zoneShardSets(shards []ShardingTopoShardInfo) map[string]map[string]bool {
+	zoneShards := make(map[string]map[string]bool)
+	for _, shard := range shards {
+		for _, zone := range shard.Tags {
+			if zoneShards[zone] == nil {
+				zoneShards[zone] = make(map[string]bool)
+			}
+			zoneShards[zone][shard.Shard] = true
+		}
+	}
+	return zoneShards
+}
+
+// zoneChunksPipeline returns an aggregation on config.chunks that counts the
+// chunks fully within every zone range per shard, in a single pass ($switch
+// needs version 3.4). The chunks of a collection are matched by ns, or by uuid
+// since version 5.0.
+func zoneChunksPipeline(ranges []ShardingZoneRange, uuidByNs map[string]*bson.Binary) []bson.M {
+	collections := make([]bson.M, 0, len(ranges))
+	seen := make(map[string]bool)
+	branches := make([]bson.M, 0, len(ranges))
+	for i, zoneRange := range ranges {
+		sameCollection := []interface{}{bson.M{"$eq": []interface{}{"$ns", zoneRange.Ns}}}
+		uuid := uuidByNs[zoneRange.Ns]
+		if uuid != nil {
+			sameCollection = append(sameCollection, bson.M{"$eq": []interface{}{"$uuid", uuid}})
+		}
+		if !seen[zoneRange.Ns] {
+			seen[zoneRange.Ns] = true
+			collections = append(collections, bson.M{"ns": zoneRange.Ns})
+			if uuid != nil {
+				collections = append(collections, bson.M{"uuid": uuid})
+			}
+		}
+		// the bounds are literals, shard key values may look like expressions
+		branches = append(branches, bson.M{
+			"case": bson.M{"$and": []interface{}{
+				bson.M{"$or": sameCollection},
+				bson.M{"$gte": []interface{}{"$min", bson.M{"$literal": zoneRange.Min}}},
+				bson.M{"$lte": []interface{}{"$max", bson.M{"$literal": zoneRange.Max}}},
+			}},
+			"then": i,
+		})
+	}
+	return []bson.M{
+		{"$match": bson.M{"$or": collections}},
+		{"$project": bson.M{"shard": 1, "range": bson.M{"$switch": bson.M{"branches": branches, "default": -1}}}},
+		{"$match": bson.M{"range": bson.M{"$gte": 0}}},
+		{"$group": bson.M{"_id": bson.M{"range": "$range", "shard": "$shard"}, "count": bson.M{"$sum": 1}}},
+	}
+}
+
+// addRanges counts the zone ranges per collection and zone, and reports 0
+// misplaced chunks for every zone range
+func (status *ShardingZoneStats) addRanges(ranges []ShardingZoneRange) {
+	for _, zoneRange := range ranges {
+		key := shardingZoneKey{Ns: zoneRange.Ns, Zone: zoneRange.Zone}
+		status.Ranges[key]++
+		if _, ok := status.MisplacedChunks[key]; !ok {
+			status.MisplacedChunks[key] = 0
+		}
+	}
+}
+
+// addChunks adds the chunks of a shard within a zone range, they are
+// misplaced when the shard is not in the zone
+func (status *ShardingZoneStats) addChunks(zoneRange ShardingZoneRange, shard string, chunks float64, zoneShards map[string]map[string]bool) {
+	status.Chunks[shardingZoneChunksKey{Ns: zoneRange.Ns, Zone: zoneRange.Zone, Shard: shard}] += chunks
+	if !zoneShards[zoneRange.Zone][shard] {
+		status.MisplacedChunks[shardingZoneKey{Ns: zoneRange.Ns, Zone: zoneRange.Zone}] += chunks
+	}
+}
+
+// GetShardingZoneStatus returns the zone ranges, the shards of every zone and
+// the number of chunks per shard within the zone ranges. Chunks fully within a
+// zone range are matched with the BSON comparison of their bounds.
+func GetShardingZoneStatus(session *mgo.Session, shards *[]ShardingTopoShardInfo) *ShardingZoneStats {
+	results := &ShardingZoneStats{
+		Ranges:          make(map[shardingZoneKey]float64),
+		ZoneShards:      make(map[string]float64),
+		Chunks:          make(map[shardingZoneChunksKey]float64),
+		MisplacedChunks: make(map[shardingZoneKey]float64),
+	}
+
+	var zoneShards map[string]map[string]bool
+	if shards != nil {
+		zoneShards = zoneShardSets(*shards)
+	}
+	for zone, members := range zoneShards {
+		results.ZoneShards[zone] = float64(len(members))
+	}
+
+	ranges := GetShardingZoneRanges(session)
+	if len(ranges) == 0 {
+		return results
+	}
+	results.addRanges(ranges)
+
+	// config.chunks is keyed by the collection uuid instead of ns since version 5.0
+	uuidByNs := make(map[string]*bson.Binary)
+	for _, collection := range GetShardedCollections(session) {
+		uuidByNs[collection.Ns] = collection.UUID
+	}
+
+	var counts []shardingZoneRangeChunks
+	err := session.DB("config").C("chunks").Pipe(zoneChunksPipeline(ranges, uuidByNs)).All(&counts)
+	if err != nil {
+		glog.Errorf("Failed to execute aggregate query on 'config.chunks': %s", err)
+		return results
+	}
+	for _, count := range counts {
+		if count.Id.Range < 0 || count.Id.Range >= len(ranges) {
+			continue
+		}
+		results.addChunks(ranges[count.Id.Range], count.Id.Shard, count.Chunks, zoneShards)
+	}
+
+	return results
+}
@@ -0,0 +1,93 @@
+package collector_mongos
+
+import (
+	"testing"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func zoneRangeBound(value int) bson.Raw {
+	data, err := bson.Marshal(bson.M{"x": value})
+	if err != nil {
+		panic(err)
+	}
+	return bson.Raw{Kind: 3, Data: data}
+}
+
+func Test_ZoneShardSets(t *testing.T) {
+	zoneShards := zoneShardSets([]ShardingTopoShardInfo{
+		{Shard: "rs0", Tags: []string{"EU", "US"}},
+		{Shard: "rs1", Tags: []string{"EU"}},
+		{Shard: "rs2"},
+	})
+	if len(zoneShards) != 2 || len(zoneShards["EU"]) != 2 || !zoneShards["US"]["rs0"] || zoneShards["US"]["rs1"] {
+		t.Errorf("Unexpected zone shards %v", zoneShards)
+	}
+}
+
+func Test_ZoneChunksPipeline(t *testing.T) {
+	uuid := &bson.Binary{Kind: 4, Data: []byte("0123456789abcdef")}
+	ranges := []ShardingZoneRange{
+		{Ns: "test.a", Zone: "EU", Min: zoneRangeBound(0), Max: zoneRangeBound(10)},
+		{Ns: "test.a", Zone: "US", Min: zoneRangeBound(10), Max: zoneRangeBound(20)},
+		{Ns: "test.b", Zone: "EU", Min: zoneRangeBound(0), Max: zoneRangeBound(10)},
+	}
+	pipeline := zoneChunksPipeline(ranges, map[string]*bson.Binary{"test.a": uuid})
+	if len(pipeline) != 4 {
+		t.Fatalf("Expected a single pipeline of 4 stages, got %d", len(pipeline))
+	}
+
+	// the collections are matched once, by ns and by uuid when it is known
+	collections := pipeline[0]["$match"].(bson.M)["$or"].([]bson.M)
+	if len(collections) != 3 || collections[0]["ns"] != "test.a" || collections[1]["uuid"] != uuid || collections[2]["ns"] != "test.b" {
+		t.Errorf("Unexpected collection match %v", collections)
+	}
+
+	branches := pipeline[1]["$project"].(bson.M)["range"].(bson.M)["$switch"].(bson.M)["branches"].([]bson.M)
+	if len(branches) != len(ranges) || branches[1]["then"] != 1 {
+		t.Errorf("Expected one branch per zone range, got %v", branches)
+	}
+	sameCollection := branches[0]["case"].(bson.M)["$and"].([]interface{})[0].(bson.M)["$or"].([]interface{})
+	if len(sameCollection) != 2 {
+		t.Errorf("Expected the first range to match by ns and uuid, got %v", sameCollection)
+	}
+
+	if _, err := bson.Marshal(bson.M{"pipeline": pipeline}); err != nil {
+		t.Errorf("The pipeline cannot be encoded: %s", err)
+	}
+}
+
+func Test_ShardingZoneStatsAddChunks(t *testing.T) {
+	status := &ShardingZoneStats{
+		Ranges:          make(map[shardingZoneKey]float64),
+		ZoneShards:      make(map[string]float64),
+		Chunks:          make(map[shardingZoneChunksKey]float64),
+		MisplacedChunks: make(map[shardingZoneKey]float64),
+	}
+	ranges := []ShardingZoneRange{
+		{Ns: "test.a", Zone: "EU"},
+		{Ns: "test.a", Zone: "EU"},
+		{Ns: "test.a", Zone: "US"},
+	}
+	zoneShards := map[string]map[string]bool{"EU": {"rs0": true}, "US": {"rs1": true}}
+
+	status.addRanges(ranges)
+	status.addChunks(ranges[0], "rs0", 4, zoneShards)
+	status.addChunks(ranges[0], "rs1", 2, zoneShards)
+	status.addChunks(ranges[1], "rs1", 3, zoneShards)
+
+	eu := shardingZoneKey{Ns: "test.a", Zone: "EU"}
+	us := shardingZoneKey{Ns: "test.a", Zone: "US"}
+	if status.Ranges[eu] != 2 || status.Ranges[us] != 1 {
+		t.Errorf("Unexpected ranges %v", status.Ranges)
+	}
+	if status.Chunks[shardingZoneChunksKey{Ns: "test.a", Zone: "EU", Shard: "rs1"}] != 5 {
+		t.Errorf("Unexpected chunks %v", status.Chunks)
+	}
+	if status.MisplacedChunks[eu] != 5 {
+		t.Errorf("Expected the 5 chunks of the EU ranges on rs1 to be misplaced, got %v", status.MisplacedChunks[eu])
+	}
+	if misplaced, ok := status.MisplacedChunks[us]; !ok || misplaced != 0 {
+		t.Errorf("Expected 0 misplaced chunks for a zone range without chunks, got %v", status.MisplacedChunks)
+	}
+}
@@ -0,0 +1,60 @@
+package collector_mongos
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func Test_ParserTransactions(t *testing.T) {
+	jobTime := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	data, _ := bson.Marshal(bson.M{
+		"logicalSessionRecordCache": bson.M{
+			"activeSessionsCount":                     int32(4),
+			"sessionsCollectionJobCount":              int32(20),
+			"lastSessionsCollectionJobDurationMillis": int32(15),
+			"lastTransactionReaperJobTimestamp":       jobTime,
+		},
+		"transactions": bson.M{
+			"currentOpen":                int64(2),
+			"totalStarted":               int64(100),
+			"totalCommitted":             int64(90),
+			"totalContactedParticipants": int64(250),
+			"abortCause":                 bson.M{"NoSuchTransaction": int64(4)},
+			"commitTypes": bson.M{
+				"twoPhaseCommit": bson.M{"initiated": int64(30), "successful": int64(29), "successfulDurationMicros": int64(1500000)},
+			},
+		},
+	})
+
+	status := &ServerStatus{}
+	if err := bson.Unmarshal(data, status); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions := status.LogicalSessionRecordCache
+	if sessions == nil {
+		t.Fatal("LogicalSessionRecordCache group was not loaded")
+	}
+	if sessions.ActiveSessionsCount != 4 || sessions.SessionsCollectionJobCount != 20 {
+		t.Errorf("logical session counters were not loaded: %+v", sessions)
+	}
+	if sessions.LastSessionsCollectionJobTimestamp != nil {
+		t.Errorf("Expected no sessions collection job timestamp, got %v", sessions.LastSessionsCollectionJobTimestamp)
+	}
+	if sessions.LastTransactionReaperJobTimestamp == nil || !sessions.LastTransactionReaperJobTimestamp.Equal(jobTime) {
+		t.Errorf("Expected the transaction reaper job timestamp %v, got %v", jobTime, sessions.LastTransactionReaperJobTimestamp)
+	}
+
+	transactions := status.Transactions
+	if transactions == nil {
+		t.Fatal("Transactions group was not loaded")
+	}
+	if transactions.TotalStarted != 100 || transactions.TotalContactedParticipants != 250 || transactions.AbortCause["NoSuchTransaction"] != 4 {
+		t.Errorf("transaction counters were not loaded: %+v", transactions)
+	}
+	if commit := transactions.CommitTypes["twoPhaseCommit"]; commit.Initiated != 30 || commit.SuccessfulDurationMicros != 1500000 {
+		t.Errorf("commit types were not loaded: %+v", transactions.CommitTypes)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/percona/mongodb_exporter/collector"
 	"github.com/percona/mongodb_exporter/shared"
@@ -44,6 +45,15 @@ var (
 		"    \tIf provided: MongoDB servers connecting to should present a certificate signed by one of this CAs.\n"+
 		"    \tIf not provided: System default CAs are used.")
 	mongodbTlsDisableHostnameValidation = flag.Bool("mongodb.tls-disable-hostname-validation", false, "Do hostname validation for server connection.")
+	mongodbTCMallocVerbosity            = flag.Int("mongodb.tcmalloc-verbosity", 1, "Verbosity of the serverStatus tcmalloc section when the 'tcmalloc' group is enabled, 2 adds the per-size-class breakdown.")
+	mongodbTransactionsCurrentOp        = flag.Bool("mongodb.transactions-currentop", false, "Report the age of the oldest open transaction from $currentOp when the 'transactions' group is enabled (4.0+).")
+	mongodbOplogSamplerMaxDocs          = flag.Int("mongodb.oplog-sampler-max-docs", 10000, "Maximum number of oplog entries read per scrape when the 'oplog_sampler' group is enabled.")
+	mongodbOplogSamplerMaxTime          = flag.Duration("mongodb.oplog-sampler-max-time", 2*time.Second, "Maximum time spent reading the oplog per scrape when the 'oplog_sampler' group is enabled.")
+	mongodbOplogSamplerMaxNamespaces    = flag.Int("mongodb.oplog-sampler-max-namespaces", 100, "Maximum number of ns label values exported when the 'oplog_sampler' group is enabled, the entries of the other namespaces are counted as ns \"other\" (0 = no limit).")
+	mongodbChangelogWindow              = flag.Duration("mongodb.sharding-changelog-window", 10*time.Minute, "Trailing window of config.changelog events reported by the legacy changelog_10min_total metric.")
+	mongodbConnPoolMaxHosts             = flag.Int("mongodb.connpool-max-hosts", 50, "Maximum number of host label values exported when the 'connpool' group is enabled, the other hosts are summed as host \"other\" (0 = no limit).")
+	mongodbMongosStaleAfter             = flag.Duration("mongodb.mongos-stale-after", 10*time.Minute, "Age of the last config.mongos ping after which a mongos router is reported as stale.")
+	mongodbChunkSizesMaxTime            = flag.Duration("mongodb.chunk-sizes-max-time", 5*time.Second, "Maximum time spent estimating chunk sizes with dataSize per scrape when the 'chunk_sizes' group is enabled.")
 )
 
 var landingPage = []byte(`<html>
@@ -182,6 +192,15 @@ func registerCollector() {
 		TLSPrivateKeyFile:     *mongodbTlsPrivateKey,
 		TLSCaFile:             *mongodbTlsCa,
 		TLSHostnameValidation: !(*mongodbTlsDisableHostnameValidation),
+		TCMallocVerbosity:     *mongodbTCMallocVerbosity,
+		TransactionsCurrentOp: *mongodbTransactionsCurrentOp,
+		OplogSamplerMaxDocs:   *mongodbOplogSamplerMaxDocs,
+		OplogSamplerMaxTime:   *mongodbOplogSamplerMaxTime,
+		OplogSamplerMaxNs:     *mongodbOplogSamplerMaxNamespaces,
+		ChunkSizesMaxTime:     *mongodbChunkSizesMaxTime,
+		ChangelogWindow:       *mongodbChangelogWindow,
+		ConnPoolMaxHosts:      *mongodbConnPoolMaxHosts,
+		MongosStaleAfter:      *mongodbMongosStaleAfter,
 	})
 	prometheus.MustRegister(mongodbCollector)
 }
@@ -196,6 +215,16 @@ func main() {
 
 	shared.ParseEnabledGroups(*enabledGroupsFlag)
 
+	if *mongodbOplogSamplerMaxDocs <= 0 {
+		panic("The flag -mongodb.oplog-sampler-max-docs must be greater than 0")
+	}
+	if *mongodbOplogSamplerMaxTime <= 0 {
+		panic("The flag -mongodb.oplog-sampler-max-time must be greater than 0")
+	}
+	if *mongodbChunkSizesMaxTime <= 0 {
+		panic("The flag -mongodb.chunk-sizes-max-time must be greater than 0")
+	}
+
 	fmt.Println("### Warning: the exporter is in beta/experimental state and field names are very\n### likely to change in the future and features may change or get removed!\n### See: https://github.com/percona/mongodb_exporter for updates")
 
 	startWebServer()
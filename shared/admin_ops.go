@@ -0,0 +1,468 @@
+package shared
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// AdminOpsMetrics keeps the metrics of the admin_ops group of a collector,
+// mongod and mongos export the same metrics in their own namespace
+type AdminOpsMetrics struct {
+	adminOpRunning                     *prometheus.GaugeVec
+	adminOpProgressRatio               *prometheus.GaugeVec
+	adminOpElapsedSeconds              *prometheus.GaugeVec
+	reshardingState                    *prometheus.GaugeVec
+	reshardingPhaseElapsedSeconds      *prometheus.GaugeVec
+	reshardingRemainingSecondsEstimate *prometheus.GaugeVec
+	reshardingDocuments                *prometheus.GaugeVec
+	reshardingBytes                    *prometheus.GaugeVec
+	reshardingOplogEntries             *prometheus.GaugeVec
+	reshardingCriticalSectionWrites    *prometheus.GaugeVec
+}
+
+// NewAdminOpsMetrics returns the admin_ops metrics of a namespace
+func NewAdminOpsMetrics(namespace string) *AdminOpsMetrics {
+	return &AdminOpsMetrics{
+		adminOpRunning: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "admin_op",
+			Name:      "running",
+			Help:      "The number of running index builds, compact, renameCollection and reshardCollection operations reported by currentOp",
+		}, []string{"op", "ns"}),
+		adminOpProgressRatio: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "admin_op",
+			Name:      "progress_ratio",
+			Help:      "The progress of the running admin operations between 0 and 1, from progress.done/progress.total of currentOp or the documents copied by the resharding recipients. The least advanced operation is reported when several run on a namespace.",
+		}, []string{"op", "ns"}),
+		adminOpElapsedSeconds: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "admin_op",
+			Name:      "elapsed_seconds",
+			Help:      "The time the running admin operations have been running, the longest is reported when several run on a namespace",
+		}, []string{"op", "ns"}),
+		reshardingState: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "resharding",
+			Name:      "state",
+			Help:      "The state of the coordinator, donors and recipients of a running reshardCollection as a stateset: 1 for the current state, 0 for the other states of the role (5.0+)",
+		}, []string{"ns", "role", "shard", "state"}),
+		reshardingPhaseElapsedSeconds: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "resharding",
+			Name:      "phase_elapsed_seconds",
+			Help:      "The time spent by the donors and recipients of a running reshardCollection in the cloning, applying and critical_section phases (5.0+)",
+		}, []string{"ns", "role", "shard", "phase"}),
+		reshardingRemainingSecondsEstimate: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "resharding",
+			Name:      "remaining_seconds_estimate",
+			Help:      "The remaining time of a running reshardCollection estimated by its recipients (5.0+)",
+		}, []string{"ns", "shard"}),
+		reshardingDocuments: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "resharding",
+			Name:      "documents",
+			Help:      "The approximate number of documents to copy and the documents copied by the recipients of a running reshardCollection (5.0+)",
+		}, []string{"ns", "shard", "type"}),
+		reshardingBytes: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "resharding",
+			Name:      "bytes",
+			Help:      "The approximate number of bytes to copy and the bytes copied by the recipients of a running reshardCollection (5.0+)",
+		}, []string{"ns", "shard", "type"}),
+		reshardingOplogEntries: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "resharding",
+			Name:      "oplog_entries",
+			Help:      "The number of donor oplog entries fetched and applied by the recipients of a running reshardCollection (5.0+)",
+		}, []string{"ns", "shard", "type"}),
+		reshardingCriticalSectionWrites: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "resharding",
+			Name:      "critical_section_writes",
+			Help:      "The number of writes attempted on the donors of a running reshardCollection during its critical section (5.0+)",
+		}, []string{"ns", "shard"}),
+	}
+}
+
+// reshardingStates lists the states reported in currentOp by every role of a
+// resharding operation, to export them as a stateset
+var reshardingStates = map[string][]string{
+	"coordinator": {"unused", "initializing", "preparing-to-donate", "cloning", "applying", "blocking-writes", "aborting", "committing", "quiesced", "done"},
+	"donor":       {"unused", "preparing-to-donate", "donating-initial-data", "donating-oplog-entries", "preparing-to-block-writes", "error", "blocking-writes", "done"},
+	"recipient":   {"unused", "awaiting-fetch-timestamp", "creating-collection", "cloning", "applying", "error", "strict-consistency", "done"},
+}
+
+// AdminOpProgress is the progress document of a currentOp operation
+type AdminOpProgress struct {
+	Done  float64 `bson:"done"`
+	Total float64 `bson:"total"`
+}
+
+// AdminOp represents a document returned by the $currentOp aggregation stage,
+// the resharding fields are only set on the resharding coordinator, donor and
+// recipient operations (5.0+)
+type AdminOp struct {
+	Desc               string           `bson:"desc"`
+	Ns                 string           `bson:"ns"`
+	Shard              string           `bson:"shard"`
+	Msg                string           `bson:"msg"`
+	Progress           *AdminOpProgress `bson:"progress"`
+	SecsRunning        float64          `bson:"secs_running"`
+	MicrosecsRunning   float64          `bson:"microsecs_running"`
+	Command            bson.M           `bson:"command"`
+	OriginatingCommand bson.M           `bson:"originatingCommand"`
+
+	CoordinatorState                    string  `bson:"coordinatorState"`
+	DonorState                          string  `bson:"donorState"`
+	RecipientState                      string  `bson:"recipientState"`
+	TotalOperationTimeElapsedSecs       float64 `bson:"totalOperationTimeElapsedSecs"`
+	RemainingOperationTimeEstimatedSecs float64 `bson:"remainingOperationTimeEstimatedSecs"`
+	ApproxDocumentsToCopy               float64 `bson:"approxDocumentsToCopy"`
+	DocumentsCopied                     float64 `bson:"documentsCopied"`
+	ApproxBytesToCopy                   float64 `bson:"approxBytesToCopy"`
+	BytesCopied                         float64 `bson:"bytesCopied"`
+	TotalCopyTimeElapsedSecs            float64 `bson:"totalCopyTimeElapsedSecs"`
+	OplogEntriesFetched                 float64 `bson:"oplogEntriesFetched"`
+	OplogEntriesApplied                 float64 `bson:"oplogEntriesApplied"`
+	TotalApplyTimeElapsedSecs           float64 `bson:"totalApplyTimeElapsedSecs"`
+	CountWritesDuringCriticalSection    float64 `bson:"countWritesDuringCriticalSection"`
+	TotalCriticalSectionTimeElapsedSecs float64 `bson:"totalCriticalSectionTimeElapsedSecs"`
+}
+
+// commandNs returns the namespace of the collection named by field in a
+// command, renameCollection and reshardCollection take a full namespace while
+// the other commands take a collection name of the database of ns
+func commandNs(command bson.M, field string, ns string) (string, bool) {
+	collection, ok := command[field].(string)
+	if !ok {
+		return "", false
+	}
+	if strings.Contains(collection, ".") && (field == "renameCollection" || field == "reshardCollection") {
+		return collection, true
+	}
+	db := ns
+	if i := strings.Index(ns, "."); i >= 0 {
+		db = ns[:i]
+	}
+	return db + "." + collection, true
+}
+
+// isIndexBuildThread returns true for the thread building an index, which
+// the createIndexes commands wait on since 4.4
+func (op *AdminOp) isIndexBuildThread() bool {
+	return strings.HasPrefix(op.Msg, "Index Build") || strings.HasPrefix(op.Desc, "IndexBuildsCoordinator")
+}
+
+// reshardingRole returns the role of a resharding operation, or an empty
+// string for the other operations
+func (op *AdminOp) reshardingRole() string {
+	switch {
+	case op.CoordinatorState != "" || strings.HasPrefix(op.Desc, "ReshardingCoordinator"):
+		return "coordinator"
+	case op.DonorState != "" || strings.HasPrefix(op.Desc, "ReshardingDonor"):
+		return "donor"
+	case op.RecipientState != "" || strings.HasPrefix(op.Desc, "ReshardingRecipient"):
+		return "recipient"
+	}
+	return ""
+}
+
+// reshardingCurrentState returns the state of a resharding operation in its role
+func (op *AdminOp) reshardingCurrentState(role string) string {
+	switch role {
+	case "coordinator":
+		return op.CoordinatorState
+	case "donor":
+		return op.DonorState
+	case "recipient":
+		return op.RecipientState
+	}
+	return ""
+}
+
+// classify returns the kind of admin operation and the namespace it works
+// on, the kind is empty for the operations that are not tracked
+func (op *AdminOp) classify() (string, string) {
+	if ns, ok := commandNs(op.OriginatingCommand, "reshardCollection", op.Ns); ok {
+		return "reshard_collection", ns
+	}
+	if op.reshardingRole() != "" {
+		return "reshard_collection", op.Ns
+	}
+	if ns, ok := commandNs(op.Command, "reshardCollection", op.Ns); ok {
+		return "reshard_collection", ns
+	}
+	if ns, ok := commandNs(op.Command, "createIndexes", op.Ns); ok {
+		return "index_build", ns
+	}
+	if op.isIndexBuildThread() {
+		return "index_build", op.Ns
+	}
+	if ns, ok := commandNs(op.Command, "compact", op.Ns); ok {
+		return "compact", ns
+	}
+	if ns, ok := commandNs(op.Command, "renameCollection", op.Ns); ok {
+		return "rename_collection", ns
+	}
+	return "", ""
+}
+
+// progressRatio returns the progress of the operation between 0 and 1, or
+// false when the operation does not report its progress
+func (op *AdminOp) progressRatio() (float64, bool) {
+	if op.Progress != nil && op.Progress.Total > 0 {
+		return op.Progress.Done / op.Progress.Total, true
+	}
+	if op.reshardingRole() == "recipient" && op.ApproxDocumentsToCopy > 0 {
+		ratio := op.DocumentsCopied / op.ApproxDocumentsToCopy
+		if ratio > 1 {
+			// the number of documents to copy is an estimate
+			ratio = 1
+		}
+		return ratio, true
+	}
+	return 0, false
+}
+
+// elapsedSeconds returns the time the operation has been running
+func (op *AdminOp) elapsedSeconds() float64 {
+	switch {
+	case op.TotalOperationTimeElapsedSecs > 0:
+		return op.TotalOperationTimeElapsedSecs
+	case op.MicrosecsRunning > 0:
+		return op.MicrosecsRunning / 1000000
+	}
+	return op.SecsRunning
+}
+
+// runningRank returns how an operation of a kind counts in the number of
+// running operations: only the operations of the highest rank of a namespace
+// are counted, so that an index build is counted once by its build thread and
+// not by the createIndexes commands waiting on it, and a resharding is counted
+// once by its coordinator or its reshardCollection command and not by every
+// donor and recipient. Operations of rank 0 count as a single operation.
+func (op *AdminOp) runningRank(kind string) int {
+	switch kind {
+	case "index_build":
+		if op.Progress != nil || op.isIndexBuildThread() {
+			return 1
+		}
+	case "reshard_collection":
+		if op.reshardingRole() == "coordinator" {
+			return 2
+		}
+		if _, ok := op.Command["reshardCollection"]; ok {
+			return 1
+		}
+	default:
+		return 1
+	}
+	return 0
+}
+
+// adminOpKey identifies the admin operations of a kind on a namespace
+type adminOpKey struct {
+	op string
+	ns string
+}
+
+// adminOpShardCount keeps the number of running operations of the highest
+// rank of a kind on a namespace of a shard
+type adminOpShardCount struct {
+	running float64
+	rank    int
+}
+
+// adminOpSummary keeps the number of running operations of a kind on a
+// namespace, the least advanced progress and the longest elapsed time of all
+// the operations of the group
+type adminOpSummary struct {
+	running  float64
+	shards   map[string]*adminOpShardCount
+	progress float64
+	hasRatio bool
+	elapsed  float64
+}
+
+// summarizeAdminOps groups the tracked operations by kind and namespace. The
+// operations are counted on each shard, mongos reports a cluster wide
+// operation once per shard it runs on, and the running number of a group is
+// the highest count of its shards. All the operations run on the same shard,
+// with an empty name, on mongod.
+func summarizeAdminOps(ops []AdminOp) map[adminOpKey]*adminOpSummary {
+	summaries := make(map[adminOpKey]*adminOpSummary)
+	for i := range ops {
+		kind, ns := ops[i].classify()
+		if kind == "" {
+			continue
+		}
+		key := adminOpKey{op: kind, ns: ns}
+		summary, ok := summaries[key]
+		if !ok {
+			summary = &adminOpSummary{shards: make(map[string]*adminOpShardCount)}
+			summaries[key] = summary
+		}
+
+		rank := ops[i].runningRank(kind)
+		count, ok := summary.shards[ops[i].Shard]
+		if !ok {
+			count = &adminOpShardCount{running: 1, rank: rank}
+			summary.shards[ops[i].Shard] = count
+		} else if rank > count.rank {
+			count.running = 1
+			count.rank = rank
+		} else if rank == count.rank && rank > 0 {
+			count.running++
+		}
+		if count.running > summary.running {
+			summary.running = count.running
+		}
+
+		if ratio, ok := ops[i].progressRatio(); ok && (!summary.hasRatio || ratio < summary.progress) {
+			summary.progress = ratio
+			summary.hasRatio = true
+		}
+		if elapsed := ops[i].elapsedSeconds(); elapsed > summary.elapsed {
+			summary.elapsed = elapsed
+		}
+	}
+	return summaries
+}
+
+// boolValue converts a boolean to the value of a stateset metric
+func boolValue(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+// exportReshardingOp sets the metrics of a resharding coordinator, donor or
+// recipient operation
+func (metrics *AdminOpsMetrics) exportReshardingOp(op *AdminOp, ns string) {
+	role := op.reshardingRole()
+	current := op.reshardingCurrentState(role)
+	for _, state := range reshardingStates[role] {
+		metrics.reshardingState.WithLabelValues(ns, role, op.Shard, state).Set(boolValue(state == current))
+	}
+
+	switch role {
+	case "donor":
+		metrics.reshardingPhaseElapsedSeconds.WithLabelValues(ns, role, op.Shard, "critical_section").Set(op.TotalCriticalSectionTimeElapsedSecs)
+		metrics.reshardingCriticalSectionWrites.WithLabelValues(ns, op.Shard).Set(op.CountWritesDuringCriticalSection)
+	case "recipient":
+		metrics.reshardingPhaseElapsedSeconds.WithLabelValues(ns, role, op.Shard, "cloning").Set(op.TotalCopyTimeElapsedSecs)
+		metrics.reshardingPhaseElapsedSeconds.WithLabelValues(ns, role, op.Shard, "applying").Set(op.TotalApplyTimeElapsedSecs)
+		metrics.reshardingPhaseElapsedSeconds.WithLabelValues(ns, role, op.Shard, "critical_section").Set(op.TotalCriticalSectionTimeElapsedSecs)
+		metrics.reshardingRemainingSecondsEstimate.WithLabelValues(ns, op.Shard).Set(op.RemainingOperationTimeEstimatedSecs)
+		metrics.reshardingDocuments.WithLabelValues(ns, op.Shard, "to_copy").Set(op.ApproxDocumentsToCopy)
+		metrics.reshardingDocuments.WithLabelValues(ns, op.Shard, "copied").Set(op.DocumentsCopied)
+		metrics.reshardingBytes.WithLabelValues(ns, op.Shard, "to_copy").Set(op.ApproxBytesToCopy)
+		metrics.reshardingBytes.WithLabelValues(ns, op.Shard, "copied").Set(op.BytesCopied)
+		metrics.reshardingOplogEntries.WithLabelValues(ns, op.Shard, "fetched").Set(op.OplogEntriesFetched)
+		metrics.reshardingOplogEntries.WithLabelValues(ns, op.Shard, "applied").Set(op.OplogEntriesApplied)
+	}
+}
+
+// Export exports the running admin operations to prometheus.
+func (metrics *AdminOpsMetrics) Export(ops []AdminOp, ch chan<- prometheus.Metric) {
+	metrics.adminOpRunning.Reset()
+	metrics.adminOpProgressRatio.Reset()
+	metrics.adminOpElapsedSeconds.Reset()
+	metrics.reshardingState.Reset()
+	metrics.reshardingPhaseElapsedSeconds.Reset()
+	metrics.reshardingRemainingSecondsEstimate.Reset()
+	metrics.reshardingDocuments.Reset()
+	metrics.reshardingBytes.Reset()
+	metrics.reshardingOplogEntries.Reset()
+	metrics.reshardingCriticalSectionWrites.Reset()
+
+	for key, summary := range summarizeAdminOps(ops) {
+		metrics.adminOpRunning.WithLabelValues(key.op, key.ns).Set(summary.running)
+		if summary.hasRatio {
+			metrics.adminOpProgressRatio.WithLabelValues(key.op, key.ns).Set(summary.progress)
+		}
+		metrics.adminOpElapsedSeconds.WithLabelValues(key.op, key.ns).Set(summary.elapsed)
+	}
+	for i := range ops {
+		if ops[i].reshardingRole() == "" {
+			continue
+		}
+		_, ns := ops[i].classify()
+		metrics.exportReshardingOp(&ops[i], ns)
+	}
+
+	metrics.adminOpRunning.Collect(ch)
+	metrics.adminOpProgressRatio.Collect(ch)
+	metrics.adminOpElapsedSeconds.Collect(ch)
+	metrics.reshardingState.Collect(ch)
+	metrics.reshardingPhaseElapsedSeconds.Collect(ch)
+	metrics.reshardingRemainingSecondsEstimate.Collect(ch)
+	metrics.reshardingDocuments.Collect(ch)
+	metrics.reshardingBytes.Collect(ch)
+	metrics.reshardingOplogEntries.Collect(ch)
+	metrics.reshardingCriticalSectionWrites.Collect(ch)
+}
+
+// Describe describes the metrics for prometheus
+func (metrics *AdminOpsMetrics) Describe(ch chan<- *prometheus.Desc) {
+	metrics.adminOpRunning.Describe(ch)
+	metrics.adminOpProgressRatio.Describe(ch)
+	metrics.adminOpElapsedSeconds.Describe(ch)
+	metrics.reshardingState.Describe(ch)
+	metrics.reshardingPhaseElapsedSeconds.Describe(ch)
+	metrics.reshardingRemainingSecondsEstimate.Describe(ch)
+	metrics.reshardingDocuments.Describe(ch)
+	metrics.reshardingBytes.Describe(ch)
+	metrics.reshardingOplogEntries.Describe(ch)
+	metrics.reshardingCriticalSectionWrites.Describe(ch)
+}
+
+// GetAdminOps returns the index builds, compact, renameCollection and
+// reshardCollection operations reported by the $currentOp aggregation stage
+// with the given options, mongod lists its own operations while mongos sets
+// localOps to false to list the operations of the shards
+func GetAdminOps(session *mgo.Session, currentOp bson.M) ([]AdminOp, error) {
+	result := struct {
+		Cursor struct {
+			FirstBatch []bson.Raw `bson:"firstBatch"`
+			Id         int64      `bson:"id"`
+		} `bson:"cursor"`
+	}{}
+	pipeline := []bson.M{
+		{"$currentOp": currentOp},
+		{"$match": bson.M{"$or": []bson.M{
+			{"command.createIndexes": bson.M{"$exists": true}},
+			{"command.compact": bson.M{"$exists": true}},
+			{"command.renameCollection": bson.M{"$exists": true}},
+			{"command.reshardCollection": bson.M{"$exists": true}},
+			{"originatingCommand.reshardCollection": bson.M{"$exists": true}},
+			{"msg": bson.RegEx{Pattern: "^Index Build"}},
+			{"desc": bson.RegEx{Pattern: "^IndexBuildsCoordinator"}},
+			{"desc": bson.RegEx{Pattern: "^Resharding"}},
+		}}},
+	}
+	err := session.DB("admin").Run(bson.D{{"aggregate", 1}, {"pipeline", pipeline}, {"cursor", bson.M{}}}, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	// the operations may not fit in the first batch, the cursor of an
+	// aggregation on the admin database lives on its $cmd.aggregate namespace
+	iter := session.DB("admin").C("$cmd.aggregate").NewIter(session, result.Cursor.FirstBatch, result.Cursor.Id, nil)
+	var ops []AdminOp
+	op := AdminOp{}
+	for iter.Next(&op) {
+		ops = append(ops, op)
+		op = AdminOp{}
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	return ops, nil
+}
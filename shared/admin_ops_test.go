@@ -0,0 +1,127 @@
+package shared
+
+import (
+	"testing"
+
+	"gopkg.in/mgo.v2/bson"
+)
+
+func Test_AdminOpClassify(t *testing.T) {
+	tests := []struct {
+		op   AdminOp
+		kind string
+		ns   string
+	}{
+		{AdminOp{Ns: "test.$cmd", Command: bson.M{"createIndexes": "users"}}, "index_build", "test.users"},
+		{AdminOp{Ns: "test.users", Msg: "Index Build: scanning collection"}, "index_build", "test.users"},
+		{AdminOp{Ns: "test.$cmd", Command: bson.M{"compact": "users"}}, "compact", "test.users"},
+		{AdminOp{Ns: "admin.$cmd", Command: bson.M{"renameCollection": "test.users", "to": "other.users"}}, "rename_collection", "test.users"},
+		{AdminOp{Ns: "test.users", Desc: "ReshardingDonorService abc", DonorState: "donating-initial-data", OriginatingCommand: bson.M{"reshardCollection": "test.users"}}, "reshard_collection", "test.users"},
+		{AdminOp{Ns: "test.users", Command: bson.M{"find": "users"}}, "", ""},
+	}
+	for _, test := range tests {
+		kind, ns := test.op.classify()
+		if kind != test.kind || ns != test.ns {
+			t.Errorf("%+v was classified as %q on %q, expected %q on %q", test.op, kind, ns, test.kind, test.ns)
+		}
+	}
+}
+
+func Test_SummarizeAdminOps(t *testing.T) {
+	ops := []AdminOp{
+		{Ns: "test.users", Msg: "Index Build: scanning collection", Progress: &AdminOpProgress{Done: 50, Total: 100}, MicrosecsRunning: 30000000},
+		{Ns: "test.$cmd", Command: bson.M{"createIndexes": "users"}, SecsRunning: 45},
+		{Ns: "test.users", RecipientState: "cloning", Shard: "rs0", ApproxDocumentsToCopy: 1000, DocumentsCopied: 250, TotalOperationTimeElapsedSecs: 120},
+		{Ns: "test.users", RecipientState: "cloning", Shard: "rs1", ApproxDocumentsToCopy: 1000, DocumentsCopied: 1200, TotalOperationTimeElapsedSecs: 118},
+		{Ns: "test.users", DonorState: "donating-initial-data", Shard: "rs0", TotalOperationTimeElapsedSecs: 121},
+	}
+	summaries := summarizeAdminOps(ops)
+
+	index := summaries[adminOpKey{op: "index_build", ns: "test.users"}]
+	if index == nil || index.running != 1 || !index.hasRatio || index.progress != 0.5 || index.elapsed != 45 {
+		t.Errorf("unexpected index build summary %+v", index)
+	}
+
+	reshard := summaries[adminOpKey{op: "reshard_collection", ns: "test.users"}]
+	if reshard == nil || reshard.running != 1 || !reshard.hasRatio || reshard.progress != 0.25 || reshard.elapsed != 121 {
+		t.Errorf("unexpected resharding summary %+v", reshard)
+	}
+
+	if len(summaries) != 2 {
+		t.Errorf("expected 2 summaries, got %d", len(summaries))
+	}
+}
+
+func Test_SummarizeAdminOpsRunning(t *testing.T) {
+	ops := []AdminOp{
+		// two index builds on the same collection, each waited on by its command
+		{Ns: "test.users", Desc: "IndexBuildsCoordinatorMongod-1", Msg: "Index Build: draining writes"},
+		{Ns: "test.users", Desc: "IndexBuildsCoordinatorMongod-2"},
+		{Ns: "test.$cmd", Command: bson.M{"createIndexes": "users"}},
+		{Ns: "test.$cmd", Command: bson.M{"createIndexes": "users"}},
+		// an index build before 4.4 runs on the thread of its command
+		{Ns: "test.$cmd", Command: bson.M{"createIndexes": "orders"}, Msg: "Index Build: 10/100 10%", Progress: &AdminOpProgress{Done: 10, Total: 100}},
+		// a command waiting on a build thread that did not match
+		{Ns: "test.$cmd", Command: bson.M{"createIndexes": "items"}},
+		// the coordinator is counted rather than its donors and recipients
+		{Ns: "test.orders", Desc: "ReshardingDonorService", DonorState: "donating-initial-data", OriginatingCommand: bson.M{"reshardCollection": "test.orders"}},
+		{Ns: "test.orders", Desc: "ReshardingCoordinatorService", CoordinatorState: "cloning", OriginatingCommand: bson.M{"reshardCollection": "test.orders"}},
+		{Ns: "test.orders", Desc: "ReshardingRecipientService", RecipientState: "cloning", OriginatingCommand: bson.M{"reshardCollection": "test.orders"}},
+		{Ns: "test.$cmd", Command: bson.M{"compact": "logs"}},
+		{Ns: "test.$cmd", Command: bson.M{"compact": "logs"}},
+	}
+	summaries := summarizeAdminOps(ops)
+
+	tests := []struct {
+		key     adminOpKey
+		running float64
+	}{
+		{adminOpKey{op: "index_build", ns: "test.users"}, 2},
+		{adminOpKey{op: "index_build", ns: "test.orders"}, 1},
+		{adminOpKey{op: "index_build", ns: "test.items"}, 1},
+		{adminOpKey{op: "reshard_collection", ns: "test.orders"}, 1},
+		{adminOpKey{op: "compact", ns: "test.logs"}, 2},
+	}
+	for _, test := range tests {
+		summary := summaries[test.key]
+		if summary == nil || summary.running != test.running {
+			t.Errorf("expected %v running operations for %+v, got %+v", test.running, test.key, summary)
+		}
+	}
+}
+
+func Test_SummarizeAdminOpsShards(t *testing.T) {
+	ops := []AdminOp{}
+	for _, shard := range []string{"rs0", "rs1", "rs2"} {
+		// mongos reports a createIndexes once per shard it runs on
+		ops = append(ops,
+			AdminOp{Ns: "test.users", Shard: shard, Desc: "IndexBuildsCoordinatorMongod-1"},
+			AdminOp{Ns: "test.$cmd", Shard: shard, Command: bson.M{"createIndexes": "users"}},
+			AdminOp{Ns: "test.orders", Shard: shard, Desc: "IndexBuildsCoordinatorMongod-2"},
+			AdminOp{Ns: "test.orders", Shard: shard, Desc: "IndexBuildsCoordinatorMongod-3"},
+		)
+	}
+	// a resharding has a single coordinator and a donor and a recipient per shard
+	ops = append(ops,
+		AdminOp{Ns: "test.logs", Shard: "rs0", CoordinatorState: "cloning"},
+		AdminOp{Ns: "test.logs", Shard: "rs0", DonorState: "donating-initial-data"},
+		AdminOp{Ns: "test.logs", Shard: "rs1", DonorState: "donating-initial-data"},
+		AdminOp{Ns: "test.logs", Shard: "rs1", RecipientState: "cloning"},
+	)
+	summaries := summarizeAdminOps(ops)
+
+	tests := []struct {
+		key     adminOpKey
+		running float64
+	}{
+		{adminOpKey{op: "index_build", ns: "test.users"}, 1},
+		{adminOpKey{op: "index_build", ns: "test.orders"}, 2},
+		{adminOpKey{op: "reshard_collection", ns: "test.logs"}, 1},
+	}
+	for _, test := range tests {
+		summary := summaries[test.key]
+		if summary == nil || summary.running != test.running {
+			t.Errorf("expected %v running operations for %+v, got %+v", test.running, test.key, summary)
+		}
+	}
+}
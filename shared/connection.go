@@ -39,6 +39,9 @@ type MongoSessionOpts struct {
 	TLSPrivateKeyFile     string
 	TLSCaFile             string
 	TLSHostnameValidation bool
+	// Hosts replaces the hosts of URI when set, to connect to other members of
+	// the cluster with the same credentials and TLS options
+	Hosts []string
 }
 
 func MongoSession(opts MongoSessionOpts) *mgo.Session {
@@ -48,6 +51,11 @@ func MongoSession(opts MongoSessionOpts) *mgo.Session {
 		return nil
 	}
 
+	if len(opts.Hosts) > 0 {
+		dialInfo.Addrs = opts.Hosts
+		dialInfo.ReplicaSetName = ""
+	}
+
 	dialInfo.Direct = true // Force direct connection
 	dialInfo.Timeout = dialMongodbTimeout
 
@@ -59,7 +67,11 @@ func MongoSession(opts MongoSessionOpts) *mgo.Session {
 
 	session, err := mgo.DialWithInfo(dialInfo)
 	if err != nil {
-		glog.Errorf("Cannot connect to server using url %s: %s", RedactMongoUri(opts.URI), err)
+		if len(opts.Hosts) > 0 {
+			glog.Errorf("Cannot connect to server using hosts %s: %s", strings.Join(opts.Hosts, ","), err)
+		} else {
+			glog.Errorf("Cannot connect to server using url %s: %s", RedactMongoUri(opts.URI), err)
+		}
 		return nil
 	}
 	session.SetMode(mgo.Eventual, true)
@@ -147,9 +159,10 @@ func MongoSessionServerVersion(session *mgo.Session) (string, error) {
 
 func MongoSessionNodeType(session *mgo.Session) (string, error) {
 	masterDoc := struct {
-		SetName interface{} `bson:"setName"`
-		Hosts   interface{} `bson:"hosts"`
-		Msg     string      `bson:"msg"`
+		SetName   interface{} `bson:"setName"`
+		Hosts     interface{} `bson:"hosts"`
+		Msg       string      `bson:"msg"`
+		ConfigSvr interface{} `bson:"configsvr"`
 	}{}
 	err := session.Run("isMaster", &masterDoc)
 	if err != nil {
@@ -157,7 +170,10 @@ func MongoSessionNodeType(session *mgo.Session) (string, error) {
 		return "unknown", err
 	}
 
-	if masterDoc.SetName != nil || masterDoc.Hosts != nil {
+	if masterDoc.ConfigSvr != nil {
+		// configsvr is only reported by the members of a config server replica set (3.2+)
+		return "configsvr", nil
+	} else if masterDoc.SetName != nil || masterDoc.Hosts != nil {
 		return "replset", nil
 	} else if masterDoc.Msg == "isdbgrid" {
 		// isdbgrid is always the msg value when calling isMaster on a mongos
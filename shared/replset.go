@@ -0,0 +1,67 @@
+package shared
+
+import (
+	"time"
+)
+
+// ReplSetMemberStates lists every replica set member state by state code, to
+// export the member state as a stateset
+var ReplSetMemberStates = map[int32]string{
+	0:  "STARTUP",
+	1:  "PRIMARY",
+	2:  "SECONDARY",
+	3:  "RECOVERING",
+	4:  "FATAL",
+	5:  "STARTUP2",
+	6:  "UNKNOWN",
+	7:  "ARBITER",
+	8:  "DOWN",
+	9:  "ROLLBACK",
+	10: "REMOVED",
+}
+
+// ReplSetMemberOptime is the state and the last applied optime date of a
+// replica set member in replSetGetStatus
+type ReplSetMemberOptime struct {
+	Name       string
+	State      int32
+	OptimeDate time.Time
+}
+
+// ReplSetReferenceOptimeDate returns the optime date the replication lag is
+// computed against: the primary's, or the most recent one when there is no
+// primary.
+func ReplSetReferenceOptimeDate(members []ReplSetMemberOptime) (time.Time, bool) {
+	var latest time.Time
+	for _, member := range members {
+		if member.State == 1 {
+			return member.OptimeDate, true
+		}
+		if member.OptimeDate.After(latest) {
+			latest = member.OptimeDate
+		}
+	}
+	return latest, !latest.IsZero()
+}
+
+// ReplSetReplicationLags returns the replication lag in seconds of every member
+// behind the reference optime date. Arbiters and unreachable members do not
+// report an optime and are left out.
+func ReplSetReplicationLags(members []ReplSetMemberOptime) map[string]float64 {
+	lags := make(map[string]float64)
+	reference, ok := ReplSetReferenceOptimeDate(members)
+	if !ok {
+		return lags
+	}
+	for _, member := range members {
+		if member.OptimeDate.IsZero() {
+			continue
+		}
+		lag := reference.Sub(member.OptimeDate).Seconds()
+		if lag < 0 {
+			lag = 0
+		}
+		lags[member.Name] = lag
+	}
+	return lags
+}
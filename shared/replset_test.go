@@ -0,0 +1,31 @@
+package shared
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_ReplSetReplicationLags(t *testing.T) {
+	now := time.Now()
+	members := []ReplSetMemberOptime{
+		{Name: "a:27017", State: 2, OptimeDate: now.Add(-5 * time.Second)},
+		{Name: "b:27017", State: 1, OptimeDate: now.Add(-2 * time.Second)},
+		{Name: "c:27017", State: 2, OptimeDate: now},
+		{Name: "d:27017", State: 7},
+	}
+	lags := ReplSetReplicationLags(members)
+	if len(lags) != 3 || lags["a:27017"] != 3 || lags["b:27017"] != 0 || lags["c:27017"] != 0 {
+		t.Errorf("Unexpected replication lags behind the primary %v", lags)
+	}
+
+	// during an election the lag is computed against the most recent optime
+	members[1].State = 2
+	lags = ReplSetReplicationLags(members)
+	if len(lags) != 3 || lags["a:27017"] != 5 || lags["b:27017"] != 2 || lags["c:27017"] != 0 {
+		t.Errorf("Unexpected replication lags without a primary %v", lags)
+	}
+
+	if lags := ReplSetReplicationLags(nil); len(lags) != 0 {
+		t.Errorf("Expected no replication lag without members, got %v", lags)
+	}
+}
@@ -29,17 +29,21 @@ func ParameterizeString(text string) string {
 	return strings.ToLower(result)
 }
 
+// IsVersionGreater returns true if version is equal to or greater than major.minor.release,
+// pre-release suffixes like "-rc0" are ignored
 func IsVersionGreater(version string, major int, minor int, release int) bool {
-	split := strings.Split(version, ".")
-	cmp_major, _ := strconv.Atoi(split[0])
-	cmp_minor, _ := strconv.Atoi(split[1])
-	cmp_release, _ := strconv.Atoi(split[2])
-
-	if cmp_major >= major && cmp_minor >= minor && cmp_release >= release {
-		return true
+	split := strings.SplitN(strings.SplitN(version, "-", 2)[0], ".", 3)
+	for i, want := range []int{major, minor, release} {
+		var cmp int
+		if i < len(split) {
+			cmp, _ = strconv.Atoi(split[i])
+		}
+		if cmp != want {
+			return cmp > want
+		}
 	}
 
-	return false
+	return true
 }
 
 func LoadCaFrom(pemFile string) (*x509.CertPool, error) {
@@ -31,3 +31,25 @@ func Test_ParameterizeString(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func Test_IsVersionGreater(t *testing.T) {
+	if !IsVersionGreater("4.4.0", 4, 4, 0) {
+		t.Fail()
+	}
+
+	if !IsVersionGreater("5.0.3", 4, 4, 0) {
+		t.Fail()
+	}
+
+	if !IsVersionGreater("4.4.0-rc1", 4, 4, 0) {
+		t.Fail()
+	}
+
+	if IsVersionGreater("4.2.12", 4, 4, 0) {
+		t.Fail()
+	}
+
+	if IsVersionGreater("unknown", 3, 4, 0) {
+		t.Fail()
+	}
+}